@@ -0,0 +1,251 @@
+package sqlquerybob
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Generates the query string with each placeholder replaced by a literal of the value bound
+// to it, as returned by Args. Strings are quoted and escaped, numbers are inlined, booleans
+// become the same literals as in WhereBool, times
+// and byte slices become timestamp and binary literals of the database engine and nil
+// values become NULL. The result is meant for logging only, like slow query logs, and must
+// never be executed, since the literals are not guaranteed to be safe for every driver and
+// type. Use Build to execute the query.
+func (qb *Builder) GenerateDebugQuery() (string, error) {
+	qry, args, err := qb.Build()
+	if err != nil {
+		return "", err
+	}
+	return qb.replacePlaceholders(qry, len(args), func(index int) string {
+		return qb.literal(args[index])
+	}), nil
+}
+
+// Returns a key identifying the shape of the query, for grouping queries in metrics. It is
+// the generated query with every placeholder replaced by ? whatever the database engine,
+// its IN lists of placeholders collapsed to a single one and its whitespace collapsed, so
+// queries that only differ in their bound values, including the number of values of an
+// IN list, have the same fingerprint. Returns an empty string if the query cannot be
+// generated. The query is generated on a copy of the builder, so neither the builder nor
+// its subqueries are changed.
+func (qb *Builder) Fingerprint() string {
+	copied := *qb
+	qry, err := copied.GenerateQuery()
+	if err != nil {
+		return ""
+	}
+	qry = qb.replacePlaceholders(qry, copied.placeholderCount, func(int) string {
+		return "?"
+	})
+	return strings.Join(strings.Fields(collapsePlaceholderLists(qry)), " ")
+}
+
+// Collapses the lists of ? placeholders of IN outside string literals to a single entry,
+// like IN (?,?,?) to IN (?) and the row values IN ((?,?),(?,?)) to IN ((?,?)). Other lists,
+// like the VALUES of an insert or the arguments of a function, are left as they are.
+func collapsePlaceholderLists(qry string) string {
+	parts := strings.Split(qry, "'")
+	for i := 0; i < len(parts); i += 2 {
+		parts[i] = collapseInLists(parts[i])
+	}
+	return strings.Join(parts, "'")
+}
+
+// Collapses the lists of ? placeholders that directly follow IN ( in a part of a query
+func collapseInLists(qry string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(qry, "IN (")
+		if i < 0 {
+			b.WriteString(qry)
+			return b.String()
+		}
+		word := i == 0 || !isWordByte(qry[i-1])
+		b.WriteString(qry[:i+len("IN (")])
+		qry = qry[i+len("IN ("):]
+		if !word {
+			continue
+		}
+		if n := placeholderListLength(qry); n > 0 && strings.HasPrefix(qry[n:], ")") {
+			b.WriteString("?")
+			qry = qry[n:]
+			continue
+		}
+		if !strings.HasPrefix(qry, "(") {
+			continue
+		}
+		n := placeholderListLength(qry[1:])
+		if n == 0 || !strings.HasPrefix(qry[1+n:], ")") {
+			continue
+		}
+		row := qry[:n+2]
+		rest := qry[n+2:]
+		for strings.HasPrefix(rest, ","+row) {
+			rest = rest[len(row)+1:]
+		}
+		if strings.HasPrefix(rest, ")") {
+			b.WriteString(row)
+			qry = rest
+		}
+	}
+}
+
+// Returns the length of the list of ? placeholders separated by commas at the start of s,
+// or 0 if s does not start with a placeholder
+func placeholderListLength(s string) int {
+	n := 0
+	for n < len(s) && s[n] == '?' {
+		n++
+		if n+1 < len(s) && s[n] == ',' && s[n+1] == '?' {
+			n++
+		} else {
+			break
+		}
+	}
+	return n
+}
+
+// Checks if a byte can be part of an SQL word, like a keyword or an identifier
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// Replaces the placeholders of the query outside string literals with the result of
+// replace for the index of the arg bound to them. Placeholders bound to an index beyond
+// count are left as they are.
+func (qb *Builder) replacePlaceholders(qry string, count int, replace func(index int) string) string {
+	var b strings.Builder
+	next := 0
+	inString := false
+	for i := 0; i < len(qry); i++ {
+		c := qry[i]
+		if c == '\'' {
+			inString = !inString
+		}
+		if inString {
+			b.WriteByte(c)
+			continue
+		}
+		index, length := qb.placeholderAt(qry[i:], next)
+		if length == 0 || index >= count {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteString(replace(index))
+		next = index + 1
+		i += length - 1
+	}
+	return b.String()
+}
+
+// Returns the index of the arg bound to the placeholder at the start of qry and the length
+// of the placeholder, or a zero length if qry does not start with a placeholder. Unnumbered
+// placeholders are bound to the next arg.
+func (qb *Builder) placeholderAt(qry string, next int) (int, int) {
+	var prefix string
+	switch qb.db {
+	case POSTGRES:
+		prefix = "$"
+	case ORACLE:
+		prefix = ":"
+	case SQLSERVER:
+		prefix = "@p"
+	default:
+		if qry[0] == '?' {
+			return next, 1
+		}
+		return 0, 0
+	}
+	if !strings.HasPrefix(qry, prefix) {
+		return 0, 0
+	}
+	end := len(prefix)
+	for end < len(qry) && qry[end] >= '0' && qry[end] <= '9' {
+		end++
+	}
+	n, err := strconv.Atoi(qry[len(prefix):end])
+	if err != nil || n < 1 {
+		return 0, 0
+	}
+	return n - 1, end
+}
+
+// Returns the SQL literal of a value for the database engine of the builder
+func (qb *Builder) literal(value interface{}) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return "?"
+		}
+		value = v
+	}
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return qb.stringLiteral(v)
+	case bool:
+		return qb.boolLiteral(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	case time.Time:
+		return qb.timeLiteral(v)
+	case []byte:
+		if v == nil {
+			return "NULL"
+		}
+		return qb.bytesLiteral(v)
+	default:
+		return qb.stringLiteral(fmt.Sprint(v))
+	}
+}
+
+// Returns the string literal of a value, escaped for the client of the database engine of
+// the builder. Quotes are doubled for every database engine. MySQL also treats backslashes
+// as escape characters, so they are doubled, while PostgreSQL only does so in escape
+// strings, so a value with backslashes is generated as an E'...' string with the
+// backslashes doubled. The other database engines take backslashes literally.
+func (qb *Builder) stringLiteral(value string) string {
+	if qb.db == POSTGRES && strings.Contains(value, `\`) {
+		return "E" + strings.ReplaceAll(qb.quoteString(value), `\`, `\\`)
+	}
+	return qb.quoteString(value)
+}
+
+// Returns the timestamp literal of a time for the database engine of the builder.
+// PostgreSQL keeps the time zone offset, while the other database engines get the time as
+// it is, in its own location. SQL Server is limited to milliseconds, as for datetime
+// columns.
+func (qb *Builder) timeLiteral(value time.Time) string {
+	switch qb.db {
+	case POSTGRES:
+		return "'" + value.Format("2006-01-02 15:04:05.999999-07:00") + "'"
+	case ORACLE:
+		return "TIMESTAMP '" + value.Format("2006-01-02 15:04:05.999999") + "'"
+	case SQLSERVER:
+		return "'" + value.Format("2006-01-02T15:04:05.999") + "'"
+	default:
+		return "'" + value.Format("2006-01-02 15:04:05.999999") + "'"
+	}
+}
+
+// Returns the binary literal of a byte slice, hex encoded, for the database engine of the
+// builder
+func (qb *Builder) bytesLiteral(value []byte) string {
+	encoded := hex.EncodeToString(value)
+	switch qb.db {
+	case POSTGRES:
+		return `'\x` + encoded + "'::bytea"
+	case ORACLE:
+		return "HEXTORAW('" + strings.ToUpper(encoded) + "')"
+	case SQLSERVER:
+		return "0x" + strings.ToUpper(encoded)
+	default:
+		return "X'" + strings.ToUpper(encoded) + "'"
+	}
+}
@@ -0,0 +1,167 @@
+package sqlquerybob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItGeneratesADebugQueryWithInlinedValues(t *testing.T) {
+	var field1 string
+	expected := "SELECT table1.field1 FROM table1 WHERE table1.field1='it''s' AND table1.field2 IN (1,2.5)" +
+		" AND (table1.field3 IS NULL) OR table1.field4=NULL AND table1.field5='? $1 :1 @p1'"
+
+	assert := assert.New(t)
+	for _, db := range []database{MYSQL, POSTGRES, ORACLE, SQLSERVER} {
+		qry, err := NewSelect("table1").
+			ForDatabase(db).
+			Select("field1").
+			Into(&field1).
+			Where("table1.field1", "=", "it's").
+			Where("table1.field2", "IN", 1, 2.5).
+			WhereRaw("table1.field3 IS NULL").
+			OrWhere("table1.field4", "=", nil).
+			Where("table1.field5", "=", "? $1 :1 @p1").
+			GenerateDebugQuery()
+		assert.Nil(err)
+		assert.Equal(expected, qry)
+	}
+}
+
+func TestItEscapesTheStringsOfADebugQueryForEachDatabaseEngine(t *testing.T) {
+	expected := map[database]string{
+		MYSQL:     `DELETE FROM table1 WHERE table1.field1='it''s' AND table1.field2='C:\\\\temp\\''s'`,
+		SQLITE:    `DELETE FROM table1 WHERE table1.field1='it''s' AND table1.field2='C:\\temp\''s'`,
+		POSTGRES:  `DELETE FROM table1 WHERE table1.field1='it''s' AND table1.field2=E'C:\\\\temp\\''s'`,
+		ORACLE:    `DELETE FROM table1 WHERE table1.field1='it''s' AND table1.field2='C:\\temp\''s'`,
+		SQLSERVER: `DELETE FROM table1 WHERE table1.field1='it''s' AND table1.field2='C:\\temp\''s'`,
+	}
+
+	assert := assert.New(t)
+	for db, expectedQry := range expected {
+		qry, err := NewDelete("table1").
+			ForDatabase(db).
+			Where("table1.field1", "=", "it's").
+			Where("table1.field2", "=", `C:\\temp\'s`).
+			GenerateDebugQuery()
+		assert.Nil(err, db)
+		assert.Equal(expectedQry, qry, db)
+	}
+}
+
+func TestItGeneratesADebugQueryForAnUpdateWithTwelveValues(t *testing.T) {
+	qb := NewUpdate("table1").
+		ForPostgres().
+		Set("f1", "f2", "f3", "f4", "f5", "f6", "f7", "f8", "f9", "f10", "f11").
+		To(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, true).
+		Where("table1.id", "=", 12)
+	qry, err := qb.GenerateDebugQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("UPDATE table1 SET f1=1,f2=2,f3=3,f4=4,f5=5,f6=6,f7=7,f8=8,f9=9,f10=10,f11=TRUE"+
+		" WHERE table1.id=12", qry)
+}
+
+func TestItGeneratesTheBooleanLiteralsOfEachDatabaseEngineInADebugQuery(t *testing.T) {
+	expected := map[database]string{
+		MYSQL:     "DELETE FROM table1 WHERE table1.active=1 AND table1.deleted=0",
+		POSTGRES:  "DELETE FROM table1 WHERE table1.active=TRUE AND table1.deleted=FALSE",
+		ORACLE:    "DELETE FROM table1 WHERE table1.active=1 AND table1.deleted=0",
+		SQLSERVER: "DELETE FROM table1 WHERE table1.active=1 AND table1.deleted=0",
+	}
+
+	assert := assert.New(t)
+	for db, expectedQry := range expected {
+		qb := NewDelete("table1").
+			ForDatabase(db).
+			WhereBool("table1.active", true).
+			Where("table1.deleted", "=", false)
+		qry, err := qb.GenerateDebugQuery()
+		assert.Nil(err, db)
+		assert.Equal(expectedQry, qry, db)
+	}
+}
+
+func TestItGeneratesTheSameFingerprintForDifferentValues(t *testing.T) {
+	var field1 string
+	build := func(value string, id int) *Builder {
+		return NewSelect("table1").
+			ForPostgres().
+			Select("field1").
+			Into(&field1).
+			Where("table1.field1", "=", value).
+			WhereRaw("table1.field2 = '$1'  AND table1.id > ?", id)
+	}
+	qb := build("value1", 1)
+	qb.placeholderCount = 7
+
+	assert := assert.New(t)
+	assert.Equal("SELECT table1.field1 FROM table1 WHERE table1.field1=? AND (table1.field2 = '$1' AND table1.id > ?)", qb.Fingerprint())
+	assert.Equal(qb.Fingerprint(), build("value2", 2).Fingerprint())
+	assert.Equal(7, qb.placeholderCount)
+	assert.Equal("", NewSelect("").Fingerprint())
+}
+
+func TestItGeneratesTheSameFingerprintForListsOfDifferentLengths(t *testing.T) {
+	build := func(ids ...interface{}) *Builder {
+		return NewDelete("table1").
+			ForPostgres().
+			Where("table1.id", "IN", ids...).
+			WhereRowIn([]string{"field1", "field2"}, [][]interface{}{{1, "a"}, {2, "b"}}[:len(ids)-1]).
+			WhereJSONField("table1.data", "?,?", "=", "a")
+	}
+
+	assert := assert.New(t)
+	assert.Equal("DELETE FROM table1 WHERE table1.id IN (?) AND (field1,field2) IN ((?,?))"+
+		" AND table1.data->>'?,?'=?", build(1, 2).Fingerprint())
+	assert.Equal(build(1, 2).Fingerprint(), build(1, 2, 3).Fingerprint())
+
+	sub := NewSelect("table2").Select("table1_id").Where("table2.id", "IN", 1, 2)
+	qb := NewDelete("table1").ForPostgres().WhereInSubquery("table1.id", sub)
+	assert.Equal("DELETE FROM table1 WHERE table1.id IN (SELECT table2.table1_id FROM table2 WHERE table2.id IN (?))",
+		qb.Fingerprint())
+	qry, err := sub.GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table2.table1_id FROM table2 WHERE table2.id IN (?,?)", qry)
+}
+
+func TestItKeepsTheListsOfPlaceholdersOutsideINInFingerprints(t *testing.T) {
+	qb := NewInsert("table1").
+		ForPostgres().
+		Set("field1", "field2", "field3").
+		To("value1", 2, 3)
+
+	assert := assert.New(t)
+	assert.Equal("INSERT INTO table1 (field1,field2,field3) VALUES (?,?,?)", qb.Fingerprint())
+
+	qb = NewSelect("table1").
+		Select("id").
+		WhereRaw("COALESCE(table1.field1,?,?) NOT IN (?,?)", 1, 2, 3, 4).
+		OrderByField("table1.field2", "a", "b")
+	assert.Equal("SELECT table1.id FROM table1 WHERE (COALESCE(table1.field1,?,?) NOT IN (?))"+
+		" ORDER BY FIELD(table1.field2,?,?) ASC", qb.Fingerprint())
+}
+
+func TestItGeneratesADebugQueryWithTimeAndBytesLiteralsForEachDatabaseEngine(t *testing.T) {
+	created := time.Date(2024, 3, 9, 14, 5, 7, 250000000, time.FixedZone("", 2*60*60))
+	expected := map[database]string{
+		MYSQL:     `INSERT INTO table1 (created,data,deleted) VALUES ('2024-03-09 14:05:07.25',X'00FF1A',NULL)`,
+		SQLITE:    `INSERT INTO table1 (created,data,deleted) VALUES ('2024-03-09 14:05:07.25',X'00FF1A',NULL)`,
+		POSTGRES:  `INSERT INTO table1 (created,data,deleted) VALUES ('2024-03-09 14:05:07.25+02:00','\x00ff1a'::bytea,NULL)`,
+		ORACLE:    `INSERT INTO table1 (created,data,deleted) VALUES (TIMESTAMP '2024-03-09 14:05:07.25',HEXTORAW('00FF1A'),NULL)`,
+		SQLSERVER: `INSERT INTO table1 (created,data,deleted) VALUES ('2024-03-09T14:05:07.25',0x00FF1A,NULL)`,
+	}
+
+	assert := assert.New(t)
+	for db, expectedQry := range expected {
+		qry, err := NewInsert("table1").
+			ForDatabase(db).
+			Set("created", "data", "deleted").
+			To(created, []byte{0x00, 0xff, 0x1a}, nil).
+			GenerateDebugQuery()
+		assert.Nil(err, db)
+		assert.Equal(expectedQry, qry, db)
+	}
+}
@@ -24,6 +24,42 @@ func (e ErrBadColumnsValuesCombo) Error() string {
 	return e.msg
 }
 
+type ErrBadPlaceholdersValuesCombo struct {
+	placeholderCount int
+	valueCount       int
+	msg              string
+}
+
+func NewBadPlaceholdersValuesComboError(placeholderCount, valueCount int) ErrBadPlaceholdersValuesCombo {
+	return ErrBadPlaceholdersValuesCombo{
+		placeholderCount: placeholderCount,
+		valueCount:       valueCount,
+		msg:              fmt.Sprintf("placeholders count (%d) must be equal to values count (%d)", placeholderCount, valueCount),
+	}
+}
+
+func (e ErrBadPlaceholdersValuesCombo) Error() string {
+	return e.msg
+}
+
+type ErrUnionColumnsMismatch struct {
+	columnCount      int
+	otherColumnCount int
+	msg              string
+}
+
+func NewUnionColumnsMismatchError(columnCount, otherColumnCount int) ErrUnionColumnsMismatch {
+	return ErrUnionColumnsMismatch{
+		columnCount:      columnCount,
+		otherColumnCount: otherColumnCount,
+		msg:              fmt.Sprintf("columns count (%d) must be equal to the columns count of the combined query (%d)", columnCount, otherColumnCount),
+	}
+}
+
+func (e ErrUnionColumnsMismatch) Error() string {
+	return e.msg
+}
+
 type ErrInvalidSqlOperator struct {
 	operator string
 	msg      string
@@ -40,6 +76,202 @@ func (e ErrInvalidSqlOperator) Error() string {
 	return e.msg
 }
 
+type ErrDisallowedColumn struct {
+	column string
+	msg    string
+}
+
+func NewDisallowedColumnError(column string) ErrDisallowedColumn {
+	return ErrDisallowedColumn{
+		column: column,
+		msg:    fmt.Sprintf("column '%s' is not allowed", column),
+	}
+}
+
+func (e ErrDisallowedColumn) Error() string {
+	return e.msg
+}
+
+type ErrInvalidIdentifier struct {
+	identifier string
+	msg        string
+}
+
+func NewInvalidIdentifierError(identifier string) ErrInvalidIdentifier {
+	return ErrInvalidIdentifier{
+		identifier: identifier,
+		msg:        fmt.Sprintf("identifier '%s' is not a valid SQL identifier", identifier),
+	}
+}
+
+func (e ErrInvalidIdentifier) Error() string {
+	return e.msg
+}
+
+type ErrUnknownDatabase struct {
+	name string
+	msg  string
+}
+
+func NewUnknownDatabaseError(name string) ErrUnknownDatabase {
+	return ErrUnknownDatabase{
+		name: name,
+		msg:  fmt.Sprintf("'%s' is not a supported database engine", name),
+	}
+}
+
+func (e ErrUnknownDatabase) Error() string {
+	return e.msg
+}
+
+// Returned when the database engine of the builder does not support a feature of the query.
+// It wraps the error specific to the feature, like ErrDBEngineDoesNotSupportILike, so both
+// errors.As with this type and errors.Is with the specific error can be used. A RETURNING
+// clause on an unsupported database engine returns ErrDBEngineDoesNotSupportReturning
+// itself instead, as it always has.
+type ErrUnsupportedForDialect struct {
+	Feature string
+	DB      database
+	err     error
+	msg     string
+}
+
+func NewUnsupportedForDialectError(feature string, db database, err error) ErrUnsupportedForDialect {
+	return ErrUnsupportedForDialect{
+		Feature: feature,
+		DB:      db,
+		err:     err,
+		msg:     fmt.Sprintf("%s is not supported by database engine %v", feature, db),
+	}
+}
+
+func (e ErrUnsupportedForDialect) Error() string {
+	return e.msg
+}
+
+func (e ErrUnsupportedForDialect) Unwrap() error {
+	return e.err
+}
+
+// Returned when a value bound to a placeholder cannot be serialized to JSON with its type,
+// so it would not bind the same arg once restored
+type ErrUnsupportedJSONValue struct {
+	valueType string
+	msg       string
+}
+
+func NewUnsupportedJSONValueError(valueType string) ErrUnsupportedJSONValue {
+	return ErrUnsupportedJSONValue{
+		valueType: valueType,
+		msg:       fmt.Sprintf("value of type %s cannot be serialized to JSON", valueType),
+	}
+}
+
+func (e ErrUnsupportedJSONValue) Error() string {
+	return e.msg
+}
+
+// Returned when the same alias is given to more than one selected subquery
+type ErrDuplicateAlias struct {
+	alias string
+	msg   string
+}
+
+func NewDuplicateAliasError(alias string) ErrDuplicateAlias {
+	return ErrDuplicateAlias{
+		alias: alias,
+		msg:   fmt.Sprintf("alias %s is used by more than one selected subquery", alias),
+	}
+}
+
+func (e ErrDuplicateAlias) Error() string {
+	return e.msg
+}
+
 var ErrFirstCriterionIsOr = errors.New("the first criterion is an OR")
 
 var ErrDBEngineDoesNotSupportReturning = errors.New("database engine does not support RETURNING clause")
+
+var ErrEmptyWhereGroup = errors.New("a WHERE group has no criteria")
+
+var ErrBadBetweenValues = errors.New("BETWEEN requires exactly two values")
+
+var ErrBadOperatorValues = errors.New("comparison operator requires exactly one value")
+
+var ErrEmptyInValues = errors.New("IN / NOT IN requires at least one value")
+
+var ErrNotAStructPointer = errors.New("argument must be a pointer to a struct")
+
+var ErrNotAStruct = errors.New("argument must be a struct or a pointer to a struct")
+
+var ErrDBEngineDoesNotSupportILike = errors.New("database engine does not support ILIKE operator")
+
+var ErrNotSelectQuery = errors.New("query is not a SELECT query")
+
+var ErrCountQueryWithUnion = errors.New("count query of a query combined with UNION is not supported")
+
+var ErrEmptyTableName = errors.New("table name is empty")
+
+var ErrDBEngineDoesNotSupportNullsOrder = errors.New("database engine does not support NULLS FIRST / NULLS LAST")
+
+var ErrTruncateWithClauses = errors.New("TRUNCATE does not support WHERE, JOIN or ORDER BY clauses")
+
+var ErrDBEngineDoesNotSupportMultiTableDelete = errors.New("database engine does not support deleting from multiple tables")
+
+var ErrDBEngineDoesNotSupportUpdateJoin = errors.New("database engine does not support updates with joins")
+
+var ErrUpdateFromRequiresInnerJoin = errors.New("UPDATE ... FROM only supports INNER and CROSS joins with an ON condition")
+
+var ErrDBEngineDoesNotSupportAnyAll = errors.New("database engine does not support ANY / ALL array comparisons")
+
+var ErrDBEngineDoesNotSupportRowLock = errors.New("database engine does not support this row lock")
+
+var ErrNotInsertQuery = errors.New("query must be an insert")
+
+var ErrBadLastInsertIDDestination = errors.New("last insert id must be stored in a single pointer to an integer")
+
+var ErrDBEngineDoesNotSupportDistinctOn = errors.New("database engine does not support DISTINCT ON")
+
+var ErrDistinctWithDistinctOn = errors.New("DISTINCT and DISTINCT ON cannot be combined")
+
+var ErrInvalidOrdinal = errors.New("ORDER BY positions start from 1")
+
+var ErrDBEngineDoesNotSupportLegacyJoins = errors.New("database engine does not support the legacy Oracle join syntax")
+
+var ErrLegacyJoinNotSupported = errors.New("FULL joins and joins with USING have no legacy Oracle join syntax")
+
+var ErrDBEngineDoesNotSupportDefault = errors.New("database engine does not support DEFAULT values")
+
+var ErrEmptyCaseMapping = errors.New("CASE requires at least one WHEN value")
+
+var ErrDBEngineDoesNotSupportGreatestLeast = errors.New("database engine does not support GREATEST / LEAST")
+
+var ErrEmptyOrderByField = errors.New("ORDER BY FIELD requires at least one value")
+
+var ErrCountQueryWithGroupBy = errors.New("count query of a query with GROUP BY is not supported")
+
+var ErrHavingWithoutGroupBy = errors.New("HAVING requires GROUP BY columns")
+
+var ErrDBEngineDoesNotSupportRowValues = errors.New("database engine does not support row values")
+
+var ErrDBEngineDoesNotSupportJSONOperators = errors.New("database engine does not support JSON operators")
+
+var ErrDBEngineDoesNotSupportLimitZero = errors.New("database engine does not support LIMIT 0")
+
+var ErrPageOutOfRange = errors.New("page offset overflows")
+
+var ErrDBEngineDoesNotSupportWithTies = errors.New("database engine does not support WITH TIES")
+
+var ErrWithTiesWithoutOrderBy = errors.New("WITH TIES requires an ORDER BY clause")
+
+var ErrWithTiesWithoutLimit = errors.New("WITH TIES requires a limit")
+
+var ErrNotUpdateQuery = errors.New("query must be an update")
+
+var ErrDBEngineDoesNotSupportIndexHints = errors.New("database engine does not support index hints")
+
+var ErrDBEngineDoesNotSupportLegacyPagination = errors.New("database engine does not support the ROWNUM pagination")
+
+var ErrOffsetWithoutLimit = errors.New("offset requires a limit")
+
+var ErrOffsetFetchWithoutOrderBy = errors.New("OFFSET ... FETCH requires an ORDER BY clause")
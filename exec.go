@@ -0,0 +1,137 @@
+package sqlquerybob
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+)
+
+// Executes an insert, update, delete or truncate query on db, binding the values returned
+// by Args to its placeholders
+func (qb *Builder) Exec(db *sql.DB) (sql.Result, error) {
+	return qb.ExecContext(context.Background(), db)
+}
+
+// Executes an insert, update, delete or truncate query on db like Exec, with a context
+func (qb *Builder) ExecContext(ctx context.Context, db *sql.DB) (sql.Result, error) {
+	qry, args, err := qb.Build()
+	if err != nil {
+		return nil, err
+	}
+	return db.ExecContext(ctx, qry, args...)
+}
+
+// Executes a select query on db, binding the values returned by Args to its placeholders.
+// Each row can be scanned into the values passed to Into with rows.Scan(qb.Values()...).
+// Will return error if the query is not a select.
+func (qb *Builder) Query(db *sql.DB) (*sql.Rows, error) {
+	return qb.QueryContext(context.Background(), db)
+}
+
+// Executes a select query on db like Query, with a context
+func (qb *Builder) QueryContext(ctx context.Context, db *sql.DB) (*sql.Rows, error) {
+	qry, args, err := qb.buildSelect()
+	if err != nil {
+		return nil, err
+	}
+	return db.QueryContext(ctx, qry, args...)
+}
+
+// Executes a select query on db and scans the first row into the values passed to Into.
+// Will return sql.ErrNoRows if the query selects no rows, or error if the query is not a
+// select.
+func (qb *Builder) QueryRow(db *sql.DB) error {
+	return qb.QueryRowContext(context.Background(), db)
+}
+
+// Executes a select query on db like QueryRow, with a context
+func (qb *Builder) QueryRowContext(ctx context.Context, db *sql.DB) error {
+	qry, args, err := qb.buildSelect()
+	if err != nil {
+		return err
+	}
+	return db.QueryRowContext(ctx, qry, args...).Scan(qb.values...)
+}
+
+// Generates the query once and prepares it on db, so the statement can be cached and
+// executed multiple times. Binding the values to the placeholders is left to the caller,
+// for example with stmt.Query(qb.Args()...). The database engine of the builder must match
+// the driver of db, since the placeholders are generated for it.
+func (qb *Builder) Prepare(db *sql.DB) (*sql.Stmt, error) {
+	return qb.PrepareContext(context.Background(), db)
+}
+
+// Prepares the query on db like Prepare, with a context
+func (qb *Builder) PrepareContext(ctx context.Context, db *sql.DB) (*sql.Stmt, error) {
+	qry, err := qb.GenerateQuery()
+	if err != nil {
+		return nil, err
+	}
+	return db.PrepareContext(ctx, qry)
+}
+
+// Executes an insert query on db and stores the generated id of the column defined with
+// LastInsertIDColumn in the value passed to Into. For MySQL the id is read from the
+// LastInsertId of the result, while for the other database engines it is scanned from the
+// RETURNING clause. Without LastInsertIDColumn, the columns defined with Returning are
+// scanned into the values passed to Into, or the query is just executed if there are none.
+func (qb *Builder) ExecInsert(db *sql.DB) error {
+	return qb.ExecInsertContext(context.Background(), db)
+}
+
+// Executes an insert query on db like ExecInsert, with a context
+func (qb *Builder) ExecInsertContext(ctx context.Context, db *sql.DB) error {
+	if qb.queryType != insertQry {
+		return ErrNotInsertQuery
+	}
+	qry, args, err := qb.Build()
+	if err != nil {
+		return err
+	}
+	if qb.lastInsertID && qb.db == MYSQL {
+		if len(qb.returnValues) != 1 {
+			return ErrBadLastInsertIDDestination
+		}
+		result, err := db.ExecContext(ctx, qry, args...)
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		return storeLastInsertID(qb.returnValues[0], id)
+	}
+	if len(qb.returningColumns) > 0 {
+		return db.QueryRowContext(ctx, qry, args...).Scan(qb.returnValues...)
+	}
+	_, err = db.ExecContext(ctx, qry, args...)
+	return err
+}
+
+// Generates a select query together with its args. Will return error if the query is not
+// a select.
+func (qb *Builder) buildSelect() (string, []interface{}, error) {
+	if qb.queryType != selectQry {
+		return "", nil, ErrNotSelectQuery
+	}
+	return qb.Build()
+}
+
+// Stores a last insert id in dest, which must be a pointer to an integer
+func storeLastInsertID(dest interface{}, id int64) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return ErrBadLastInsertIDDestination
+	}
+	v = v.Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(id))
+	default:
+		return ErrBadLastInsertIDDestination
+	}
+	return nil
+}
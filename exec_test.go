@@ -0,0 +1,295 @@
+package sqlquerybob
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// An in memory database/sql driver that records the executed queries and returns the
+// configured rows and last insert id
+type fakeDB struct {
+	queries      []string
+	args         [][]driver.Value
+	lastInsertID int64
+	columns      []string
+	rows         [][]driver.Value
+}
+
+func (f *fakeDB) open() *sql.DB {
+	return sql.OpenDB(fakeConnector{f})
+}
+
+type fakeConnector struct {
+	db *fakeDB
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return fakeConn{c.db}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("open is not supported")
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{c.db, query}, nil
+}
+
+func (c fakeConn) Close() error {
+	return nil
+}
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s fakeStmt) Close() error {
+	return nil
+}
+
+func (s fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.queries = append(s.db.queries, s.query)
+	s.db.args = append(s.db.args, args)
+	return fakeResult{s.db.lastInsertID}, nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.queries = append(s.db.queries, s.query)
+	s.db.args = append(s.db.args, args)
+	return &fakeRows{columns: s.db.columns, rows: s.db.rows}, nil
+}
+
+type fakeResult struct {
+	lastInsertID int64
+}
+
+func (r fakeResult) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r fakeResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+func TestItStoresTheLastInsertIDForMySQL(t *testing.T) {
+	fake := &fakeDB{lastInsertID: 42}
+	var id int
+	qb := NewInsert("table1").
+		ForMySQL().
+		Set("field1").
+		To("value1").
+		LastInsertIDColumn("id").
+		Into(&id)
+	err := qb.ExecInsert(fake.open())
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal(42, id)
+	assert.Equal([]string{"INSERT INTO table1 (field1) VALUES (?)"}, fake.queries)
+	assert.Equal([][]driver.Value{{"value1"}}, fake.args)
+}
+
+func TestItReturnsAnErrorIfMySQLReturnsOtherColumnsThanTheLastInsertID(t *testing.T) {
+	var d struct {
+		id   int
+		name string
+	}
+	_, err := NewInsert("table1").
+		ForMySQL().
+		Set("field1").
+		To("value1").
+		LastInsertIDColumn("id").
+		Returning("name").
+		Into(&d.id, &d.name).
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Equal(ErrDBEngineDoesNotSupportReturning, err)
+}
+
+func TestItReturnsTheLastInsertIDForPostgres(t *testing.T) {
+	fake := &fakeDB{columns: []string{"id"}, rows: [][]driver.Value{{int64(7)}}}
+	var id int64
+	qb := NewInsert("table1").
+		ForPostgres().
+		Set("field1").
+		To("value1").
+		LastInsertIDColumn("id").
+		Into(&id)
+	err := qb.ExecInsert(fake.open())
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal(int64(7), id)
+	assert.Equal([]string{"INSERT INTO table1 (field1) VALUES ($1) RETURNING table1.id"}, fake.queries)
+}
+
+func TestItReturnsAnErrorIfLastInsertIDDestinationIsNotAnInteger(t *testing.T) {
+	fake := &fakeDB{lastInsertID: 42}
+	var id string
+	err := NewInsert("table1").
+		Set("field1").
+		To("value1").
+		LastInsertIDColumn("id").
+		Into(&id).
+		ExecInsert(fake.open())
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrBadLastInsertIDDestination)
+}
+
+func TestItExecutesAnUpdateWithTheCombinedArgs(t *testing.T) {
+	fake := &fakeDB{}
+	result, err := NewUpdate("table1").
+		Set("field1").
+		To("value1").
+		Where("table1.id", "=", 3).
+		Exec(fake.open())
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	affected, _ := result.RowsAffected()
+	assert.Equal(int64(1), affected)
+	assert.Equal([]string{"UPDATE table1 SET field1=? WHERE table1.id=?"}, fake.queries)
+	assert.Equal([][]driver.Value{{"value1", int64(3)}}, fake.args)
+}
+
+func TestItQueriesRowsAndScansThemIntoTheValues(t *testing.T) {
+	fake := &fakeDB{
+		columns: []string{"field1", "field2"},
+		rows:    [][]driver.Value{{"value1", int64(1)}, {"value2", int64(2)}},
+	}
+	var d struct {
+		field1 string
+		field2 int
+	}
+	qb := NewSelect("table1").
+		Select("field1", "field2").
+		Into(&d.field1, &d.field2).
+		Where("table1.field2", ">", 0)
+	rows, err := qb.Query(fake.open())
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		assert.Nil(rows.Scan(qb.Values()...))
+		got = append(got, d.field1)
+	}
+	assert.Nil(rows.Err())
+	assert.Equal([]string{"value1", "value2"}, got)
+	assert.Equal([]string{"SELECT table1.field1,table1.field2 FROM table1 WHERE table1.field2>?"}, fake.queries)
+}
+
+func TestItQueriesARowAndScansItIntoTheValues(t *testing.T) {
+	fake := &fakeDB{columns: []string{"field1"}, rows: [][]driver.Value{{"value1"}}}
+	var field1 string
+	err := NewSelect("table1").
+		Select("field1").
+		Into(&field1).
+		QueryRow(fake.open())
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("value1", field1)
+
+	fake.rows = nil
+	err = NewSelect("table1").
+		Select("field1").
+		Into(&field1).
+		QueryRow(fake.open())
+	assert.ErrorIs(err, sql.ErrNoRows)
+
+	err = NewDelete("table1").QueryRow(fake.open())
+	assert.ErrorIs(err, ErrNotSelectQuery)
+}
+
+func TestItReturnsTheContextErrorIfTheContextIsCancelled(t *testing.T) {
+	fake := &fakeDB{columns: []string{"field1"}, rows: [][]driver.Value{{"value1"}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var field1 string
+	qb := NewSelect("table1").
+		Select("field1").
+		Into(&field1)
+
+	assert := assert.New(t)
+	_, err := qb.QueryContext(ctx, fake.open())
+	assert.ErrorIs(err, context.Canceled)
+	err = qb.QueryRowContext(ctx, fake.open())
+	assert.ErrorIs(err, context.Canceled)
+	_, err = NewDelete("table1").ExecContext(ctx, fake.open())
+	assert.ErrorIs(err, context.Canceled)
+	assert.Empty(fake.queries)
+	assert.Equal("", field1)
+}
+
+func TestItPreparesAStatementThatCanBeReused(t *testing.T) {
+	fake := &fakeDB{}
+	qb := NewUpdate("table1").
+		ForPostgres().
+		Set("field1").
+		To("value1").
+		Where("table1.id", "=", 1)
+	stmt, err := qb.Prepare(fake.open())
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	defer stmt.Close()
+	_, err = stmt.Exec(qb.Args()...)
+	assert.Nil(err)
+	_, err = stmt.Exec("value2", 2)
+	assert.Nil(err)
+	qry := "UPDATE table1 SET field1=$1 WHERE table1.id=$2"
+	assert.Equal([]string{qry, qry}, fake.queries)
+	assert.Equal([][]driver.Value{{"value1", int64(1)}, {"value2", int64(2)}}, fake.args)
+}
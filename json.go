@@ -0,0 +1,643 @@
+package sqlquerybob
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The JSON representation of a builder. It holds the logical query only, so the pointer
+// values in which the results of a select or the returning columns are stored are left out.
+type builderJSON struct {
+	DB               database                        `json:"db"`
+	QueryType        queryType                       `json:"queryType"`
+	CTEs             []cteJSON                       `json:"ctes,omitempty"`
+	Table            string                          `json:"table"`
+	FromSub          *Builder                        `json:"fromSub,omitempty"`
+	Alias            string                          `json:"alias,omitempty"`
+	IndexHints       []indexHintJSON                 `json:"indexHints,omitempty"`
+	Joins            []joinJSON                      `json:"joins,omitempty"`
+	Distinct         bool                            `json:"distinct,omitempty"`
+	DistinctOn       []string                        `json:"distinctOn,omitempty"`
+	Columns          []string                        `json:"columns,omitempty"`
+	Expressions      map[string][]string             `json:"expressions,omitempty"`
+	SelectSubqueries map[string]*Builder             `json:"selectSubqueries,omitempty"`
+	SelectFunctions  map[string]selectedFunctionJSON `json:"selectFunctions,omitempty"`
+	ReturningColumns []string                        `json:"returningColumns,omitempty"`
+	DeleteTables     []string                        `json:"deleteTables,omitempty"`
+	Values           []valueJSON                     `json:"values,omitempty"`
+	Criteria         []criterionJSON                 `json:"criteria,omitempty"`
+	GroupBy          []string                        `json:"groupBy,omitempty"`
+	Having           []criterionJSON                 `json:"having,omitempty"`
+	Unions           []unionJSON                     `json:"unions,omitempty"`
+	OrderBy          []orderingJSON                  `json:"orderBy,omitempty"`
+	Limit            uint                            `json:"limit,omitempty"`
+	HasLimit         bool                            `json:"hasLimit,omitempty"`
+	Offset           uint                            `json:"offset,omitempty"`
+	WithTies         bool                            `json:"withTies,omitempty"`
+	Lock             rowLock                         `json:"lock,omitempty"`
+	LockWait         lockWait                        `json:"lockWait,omitempty"`
+	LastInsertID     bool                            `json:"lastInsertId,omitempty"`
+	LegacyJoins      bool                            `json:"legacyJoins,omitempty"`
+	LegacyPagination bool                            `json:"legacyPagination,omitempty"`
+	NoAutoPrefix     bool                            `json:"noAutoPrefix,omitempty"`
+	AllowedColumns   []string                        `json:"allowedColumns,omitempty"`
+	AllowedOperators []string                        `json:"allowedOperators,omitempty"`
+	EmptyInAsFalse   bool                            `json:"emptyInAsFalse,omitempty"`
+	SelectAll        bool                            `json:"selectAll,omitempty"`
+	ReturningAll     bool                            `json:"returningAll,omitempty"`
+}
+
+// The JSON representation of an insert / update value. A value defined with SetRaw has an
+// expression and the values bound to its placeholders, while a keyword like Default has a
+// keyword.
+type valueJSON struct {
+	Value      *jsonValue `json:"value,omitempty"`
+	Expression string     `json:"expression,omitempty"`
+	Values     jsonValues `json:"values,omitempty"`
+	Keyword    sqlKeyword `json:"keyword,omitempty"`
+}
+
+// The JSON representation of a value bound to a placeholder, which keeps its type so the
+// restored builder binds the same args. Nil values, booleans and strings are stored as
+// they are, while numbers, byte slices and times are stored in an object keyed by their
+// type, like {"int64":9007199254740993} or {"time.Time":"2024-03-09T14:05:07Z"}. Values of
+// named types, like type UserID int64, are stored as their underlying type, and values
+// that implement driver.Valuer as the value they return, which bind the same args.
+type jsonValue struct {
+	value interface{}
+}
+
+// The JSON representation of a list of values bound to placeholders
+type jsonValues []interface{}
+
+// The numeric types of the values that can be serialized, by their name
+var jsonNumericTypes = typesByName(
+	int(0), int8(0), int16(0), int32(0), int64(0),
+	uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+	float32(0), float64(0),
+)
+
+// Returns the types of values by their name
+func typesByName(values ...interface{}) map[string]reflect.Type {
+	types := make(map[string]reflect.Type, len(values))
+	for _, value := range values {
+		types[reflect.TypeOf(value).String()] = reflect.TypeOf(value)
+	}
+	return types
+}
+
+// Serializes the value with its type. Will return ErrUnsupportedJSONValue for a value
+// whose kind is not nil, bool, string, a number or []byte and that is not a time.Time, a
+// pointer to one of these or a driver.Valuer.
+func (v jsonValue) MarshalJSON() ([]byte, error) {
+	value, err := basicValue(v.value)
+	if err != nil {
+		return nil, err
+	}
+	switch value := value.(type) {
+	case nil, bool, string:
+		return json.Marshal(value)
+	}
+	return json.Marshal(map[string]interface{}{fmt.Sprintf("%T", value): value})
+}
+
+// Converts a value bound to a placeholder to the builtin type with the same kind, calling
+// driver.Valuer values and dereferencing pointers, as database/sql does when binding it
+func basicValue(value interface{}) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	if valuer, ok := value.(driver.Valuer); ok {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return nil, nil
+		}
+		driverValue, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		value, v = driverValue, reflect.ValueOf(driverValue)
+	}
+	if t, ok := value.(time.Time); ok {
+		return t, nil
+	}
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil, nil
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return basicValue(v.Elem().Interface())
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), nil
+		}
+	default:
+		if t, ok := jsonNumericTypes[v.Kind().String()]; ok {
+			return v.Convert(t).Interface(), nil
+		}
+	}
+	return nil, NewUnsupportedJSONValueError(fmt.Sprintf("%T", value))
+}
+
+// Restores a value serialized with MarshalJSON with its type. Numbers without a type are
+// restored as float64.
+func (v *jsonValue) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 || data[0] != '{' {
+		return json.Unmarshal(data, &v.value)
+	}
+	var typed map[string]json.RawMessage
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return err
+	}
+	if len(typed) != 1 {
+		return NewUnsupportedJSONValueError(string(data))
+	}
+	for name, raw := range typed {
+		value, err := unmarshalTypedValue(name, raw)
+		v.value = value
+		return err
+	}
+	return nil
+}
+
+// Restores a value stored in an object keyed by its type
+func unmarshalTypedValue(name string, raw json.RawMessage) (interface{}, error) {
+	switch name {
+	case "[]uint8":
+		var value []byte
+		err := json.Unmarshal(raw, &value)
+		return value, err
+	case "time.Time":
+		var value time.Time
+		err := json.Unmarshal(raw, &value)
+		return value, err
+	}
+	t, ok := jsonNumericTypes[name]
+	if !ok {
+		return nil, NewUnsupportedJSONValueError(name)
+	}
+	var number json.Number
+	if err := json.Unmarshal(raw, &number); err != nil {
+		return nil, err
+	}
+	value := reflect.New(t).Elem()
+	switch {
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		i, err := strconv.ParseInt(number.String(), 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		value.SetInt(i)
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64:
+		u, err := strconv.ParseUint(number.String(), 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		value.SetUint(u)
+	default:
+		f, err := strconv.ParseFloat(number.String(), t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		value.SetFloat(f)
+	}
+	return value.Interface(), nil
+}
+
+// Serializes the values with their types, as in jsonValue
+func (values jsonValues) MarshalJSON() ([]byte, error) {
+	typed := make([]jsonValue, len(values))
+	for i, value := range values {
+		typed[i] = jsonValue{value}
+	}
+	return json.Marshal(typed)
+}
+
+// Restores values serialized with MarshalJSON with their types, as in jsonValue
+func (values *jsonValues) UnmarshalJSON(data []byte) error {
+	var typed []jsonValue
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return err
+	}
+	*values = make(jsonValues, len(typed))
+	for i, value := range typed {
+		(*values)[i] = value.value
+	}
+	return nil
+}
+
+type criterionJSON struct {
+	Column     string          `json:"column,omitempty"`
+	Operator   string          `json:"operator,omitempty"`
+	Values     jsonValues      `json:"values,omitempty"`
+	Or         bool            `json:"or,omitempty"`
+	Group      []criterionJSON `json:"group,omitempty"`
+	IsGroup    bool            `json:"isGroup,omitempty"`
+	Raw        bool            `json:"raw,omitempty"`
+	Sub        *Builder        `json:"sub,omitempty"`
+	Escape     bool            `json:"escape,omitempty"`
+	Quantifier string          `json:"quantifier,omitempty"`
+	Literal    bool            `json:"literal,omitempty"`
+	RowColumns []string        `json:"rowColumns,omitempty"`
+	JSON       bool            `json:"json,omitempty"`
+	JSONKey    string          `json:"jsonKey,omitempty"`
+	Column2    string          `json:"column2,omitempty"`
+}
+
+type selectedFunctionJSON struct {
+	Name      string     `json:"name"`
+	Columns   []string   `json:"columns"`
+	Separator string     `json:"separator,omitempty"`
+	Values    jsonValues `json:"values,omitempty"`
+}
+
+type indexHintJSON struct {
+	Hint  string `json:"hint"`
+	Index string `json:"index"`
+}
+
+type joinJSON struct {
+	JoinType  string     `json:"joinType"`
+	Table     string     `json:"table"`
+	Alias     string     `json:"alias,omitempty"`
+	Column    string     `json:"column,omitempty"`
+	FKey      string     `json:"fkey,omitempty"`
+	Condition string     `json:"condition,omitempty"`
+	Values    jsonValues `json:"values,omitempty"`
+	Using     []string   `json:"using,omitempty"`
+}
+
+type orderingJSON struct {
+	Column    string     `json:"column"`
+	Direction sortOrder  `json:"direction,omitempty"`
+	Nulls     nullsOrder `json:"nulls,omitempty"`
+	Raw       bool       `json:"raw,omitempty"`
+	Ordinal   bool       `json:"ordinal,omitempty"`
+	Values    jsonValues `json:"values,omitempty"`
+}
+
+type cteJSON struct {
+	Name string   `json:"name"`
+	Sub  *Builder `json:"sub"`
+}
+
+type unionJSON struct {
+	All bool     `json:"all,omitempty"`
+	Sub *Builder `json:"sub"`
+}
+
+// Serializes the logical query of the builder to JSON, so it can be stored and restored
+// with UnmarshalJSON. The pointer values passed to Into are not serialized, since pointers
+// do not survive a round trip, so they have to be passed again with Into after restoring.
+// Will return the first error recorded while defining the query, if any, or
+// ErrUnsupportedJSONValue for a bound value that cannot be serialized with its type.
+func (qb *Builder) MarshalJSON() ([]byte, error) {
+	if qb.err != nil {
+		return nil, qb.err
+	}
+	bj := builderJSON{
+		DB:               qb.db,
+		QueryType:        qb.queryType,
+		Table:            qb.table,
+		FromSub:          qb.fromSub,
+		Alias:            qb.alias,
+		Distinct:         qb.distinct,
+		DistinctOn:       qb.distinctOn,
+		Columns:          qb.columns,
+		Expressions:      qb.expressions,
+		SelectSubqueries: qb.selectSubqueries,
+		ReturningColumns: qb.returningColumns,
+		DeleteTables:     qb.deleteTables,
+		Criteria:         criteriaToJSON(qb.criteria),
+		GroupBy:          qb.groupBy,
+		Having:           criteriaToJSON(qb.having),
+		Limit:            qb.limit,
+		HasLimit:         qb.hasLimit,
+		Offset:           qb.offset,
+		WithTies:         qb.withTies,
+		Lock:             qb.lock,
+		LockWait:         qb.lockWait,
+		LastInsertID:     qb.lastInsertID,
+		LegacyJoins:      qb.legacyJoins,
+		LegacyPagination: qb.legacyPagination,
+		NoAutoPrefix:     qb.noAutoPrefix,
+		AllowedColumns:   qb.allowedColumns,
+		AllowedOperators: qb.allowedOperators,
+		EmptyInAsFalse:   qb.emptyInAsFalse,
+		SelectAll:        qb.selectAll,
+		ReturningAll:     qb.returningAll,
+	}
+	for _, c := range qb.ctes {
+		bj.CTEs = append(bj.CTEs, cteJSON{Name: c.name, Sub: c.sub})
+	}
+	for column, f := range qb.selectFunctions {
+		if bj.SelectFunctions == nil {
+			bj.SelectFunctions = make(map[string]selectedFunctionJSON)
+		}
+		bj.SelectFunctions[column] = selectedFunctionJSON{
+			Name:      f.name,
+			Columns:   f.columns,
+			Separator: f.separator,
+			Values:    f.values,
+		}
+	}
+	for _, h := range qb.indexHints {
+		bj.IndexHints = append(bj.IndexHints, indexHintJSON{Hint: h.hint, Index: h.index})
+	}
+	for _, j := range qb.joinTables {
+		bj.Joins = append(bj.Joins, joinJSON{
+			JoinType:  j.joinType,
+			Table:     j.table,
+			Alias:     j.alias,
+			Column:    j.column,
+			FKey:      j.fkey,
+			Condition: j.condition,
+			Values:    j.values,
+			Using:     j.using,
+		})
+	}
+	if qb.queryType != selectQry {
+		for _, value := range qb.values {
+			switch v := value.(type) {
+			case rawExpression:
+				bj.Values = append(bj.Values, valueJSON{Expression: v.expression, Values: v.values})
+			case sqlKeyword:
+				bj.Values = append(bj.Values, valueJSON{Keyword: v})
+			default:
+				bj.Values = append(bj.Values, valueJSON{Value: &jsonValue{v}})
+			}
+		}
+	}
+	for _, u := range qb.unions {
+		bj.Unions = append(bj.Unions, unionJSON{All: u.all, Sub: u.sub})
+	}
+	for _, o := range qb.orderBy {
+		bj.OrderBy = append(bj.OrderBy, orderingJSON{
+			Column:    o.column,
+			Direction: o.direction,
+			Nulls:     o.nulls,
+			Raw:       o.raw,
+			Ordinal:   o.ordinal,
+			Values:    o.values,
+		})
+	}
+	return json.Marshal(bj)
+}
+
+// Restores a builder serialized with MarshalJSON, replacing everything defined on it. The
+// generated query is the same as the one of the serialized builder, once the pointer values
+// have been passed again with Into. The values bound to the placeholders are restored with
+// their types, as serialized by MarshalJSON, except that values of named types are restored
+// as their underlying type and driver.Valuer values as the value they returned.
+func (qb *Builder) UnmarshalJSON(data []byte) error {
+	var bj builderJSON
+	if err := json.Unmarshal(data, &bj); err != nil {
+		return err
+	}
+	*qb = Builder{
+		db:               bj.DB,
+		queryType:        bj.QueryType,
+		table:            bj.Table,
+		fromSub:          bj.FromSub,
+		alias:            bj.Alias,
+		distinct:         bj.Distinct,
+		distinctOn:       bj.DistinctOn,
+		columns:          bj.Columns,
+		expressions:      bj.Expressions,
+		selectSubqueries: bj.SelectSubqueries,
+		returningColumns: bj.ReturningColumns,
+		deleteTables:     bj.DeleteTables,
+		criteria:         criteriaFromJSON(bj.Criteria),
+		groupBy:          bj.GroupBy,
+		having:           criteriaFromJSON(bj.Having),
+		limit:            bj.Limit,
+		hasLimit:         bj.HasLimit || bj.Limit > 0,
+		offset:           bj.Offset,
+		withTies:         bj.WithTies,
+		lock:             bj.Lock,
+		lockWait:         bj.LockWait,
+		lastInsertID:     bj.LastInsertID,
+		legacyJoins:      bj.LegacyJoins,
+		legacyPagination: bj.LegacyPagination,
+		noAutoPrefix:     bj.NoAutoPrefix,
+		allowedColumns:   bj.AllowedColumns,
+		allowedOperators: bj.AllowedOperators,
+		emptyInAsFalse:   bj.EmptyInAsFalse,
+		selectAll:        bj.SelectAll,
+		returningAll:     bj.ReturningAll,
+	}
+	for _, c := range bj.CTEs {
+		qb.ctes = append(qb.ctes, cte{name: c.Name, sub: c.Sub})
+	}
+	for column, f := range bj.SelectFunctions {
+		if qb.selectFunctions == nil {
+			qb.selectFunctions = make(map[string]selectedFunction)
+		}
+		qb.selectFunctions[column] = selectedFunction{
+			name:      f.Name,
+			columns:   f.Columns,
+			separator: f.Separator,
+			values:    f.Values,
+		}
+	}
+	for _, h := range bj.IndexHints {
+		qb.indexHints = append(qb.indexHints, indexHint{hint: h.Hint, index: h.Index})
+	}
+	for _, j := range bj.Joins {
+		qb.joinTables = append(qb.joinTables, join{
+			joinType:  j.JoinType,
+			table:     j.Table,
+			alias:     j.Alias,
+			column:    j.Column,
+			fkey:      j.FKey,
+			condition: j.Condition,
+			values:    j.Values,
+			using:     j.Using,
+		})
+	}
+	for _, v := range bj.Values {
+		switch {
+		case v.Expression != "":
+			qb.values = append(qb.values, rawExpression{expression: v.Expression, values: v.Values})
+		case v.Keyword != "":
+			qb.values = append(qb.values, v.Keyword)
+		default:
+			var value interface{}
+			if v.Value != nil {
+				value = v.Value.value
+			}
+			qb.values = append(qb.values, value)
+		}
+	}
+	for _, u := range bj.Unions {
+		qb.unions = append(qb.unions, union{all: u.All, sub: u.Sub})
+	}
+	for _, o := range bj.OrderBy {
+		qb.orderBy = append(qb.orderBy, ordering{
+			column:    o.Column,
+			direction: o.Direction,
+			nulls:     o.Nulls,
+			raw:       o.Raw,
+			ordinal:   o.Ordinal,
+			values:    o.Values,
+		})
+	}
+	return nil
+}
+
+// Reports whether the builder defines the same logical query as another builder, which is
+// what MarshalJSON serializes, so the pointer values passed to Into are ignored. This is
+// useful in tests, where comparing generated queries is brittle across database engines.
+// Builders that have recorded an error are never equal. Builders with a bound value that
+// cannot be serialized to JSON are compared field by field instead, with reflect.DeepEqual
+// on their values.
+func (qb *Builder) Equal(other *Builder) bool {
+	return qb.Diff(other) == ""
+}
+
+// Returns the differences between the logical query of the builder and the one of another
+// builder, one line per differing field of their JSON representation, like
+//   - table: "table1" != "table2"
+//
+// Returns an empty string if the builders are equal, as in Equal. If a builder cannot be
+// serialized to JSON, the differences cannot be listed, so the error of the serialization
+// is returned instead if the builders are not equal.
+func (qb *Builder) Diff(other *Builder) string {
+	fields, err := qb.jsonFields()
+	otherFields, otherErr := other.jsonFields()
+	if err != nil || otherErr != nil {
+		if qb.equalFields(other) {
+			return ""
+		}
+		if err == nil {
+			err = otherErr
+		}
+		var unsupported ErrUnsupportedJSONValue
+		if errors.As(err, &unsupported) {
+			err = unsupported
+		}
+		return fmt.Sprintf("builders differ, but cannot be serialized to JSON to list the differences: %v", err)
+	}
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	for name := range otherFields {
+		if _, ok := fields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var diff []string
+	for _, name := range names {
+		if !bytes.Equal(fields[name], otherFields[name]) {
+			diff = append(diff, fmt.Sprintf("%s: %s != %s", name, jsonField(fields[name]), jsonField(otherFields[name])))
+		}
+	}
+	return strings.Join(diff, "\n")
+}
+
+// Reports whether the fields of the logical query of the builder are deeply equal to the
+// ones of another builder, ignoring the pointer values passed to Into. Builders that have
+// recorded an error are never equal.
+func (qb *Builder) equalFields(other *Builder) bool {
+	if qb.err != nil || other.err != nil {
+		return false
+	}
+	return reflect.DeepEqual(qb.logicalFields(), other.logicalFields())
+}
+
+// Returns a copy of the builder without the pointer values passed to Into and the state
+// of the last generated query
+func (qb *Builder) logicalFields() Builder {
+	fields := *qb
+	fields.placeholderCount = 0
+	fields.returnValues = nil
+	if fields.queryType == selectQry {
+		fields.values = nil
+	}
+	return fields
+}
+
+// Returns the fields of the JSON representation of the builder
+func (qb *Builder) jsonFields() (map[string]json.RawMessage, error) {
+	data, err := qb.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// Returns a field of the JSON representation of a builder, or - if it is not set
+func jsonField(field json.RawMessage) string {
+	if field == nil {
+		return "-"
+	}
+	return string(field)
+}
+
+// Converts criteria to their JSON representation, including the criteria of groups
+func criteriaToJSON(criteria []criterion) []criterionJSON {
+	var cj []criterionJSON
+	for _, c := range criteria {
+		cj = append(cj, criterionJSON{
+			Column:     c.column,
+			Operator:   c.operator,
+			Values:     c.values,
+			Or:         c.or,
+			Group:      criteriaToJSON(c.group),
+			IsGroup:    c.group != nil,
+			Raw:        c.raw,
+			Sub:        c.sub,
+			Escape:     c.escape,
+			Quantifier: c.quantifier,
+			Literal:    c.literal,
+			RowColumns: c.rowColumns,
+			JSON:       c.json,
+			JSONKey:    c.jsonKey,
+			Column2:    c.column2,
+		})
+	}
+	return cj
+}
+
+// Converts criteria from their JSON representation, including the criteria of groups
+func criteriaFromJSON(cj []criterionJSON) []criterion {
+	var criteria []criterion
+	for _, c := range cj {
+		var group []criterion
+		if c.IsGroup {
+			group = append([]criterion{}, criteriaFromJSON(c.Group)...)
+		}
+		criteria = append(criteria, criterion{
+			column:     c.Column,
+			operator:   c.Operator,
+			values:     c.Values,
+			or:         c.Or,
+			group:      group,
+			raw:        c.Raw,
+			sub:        c.Sub,
+			escape:     c.Escape,
+			quantifier: c.Quantifier,
+			literal:    c.Literal,
+			rowColumns: c.RowColumns,
+			json:       c.JSON,
+			jsonKey:    c.JSONKey,
+			column2:    c.Column2,
+		})
+	}
+	return criteria
+}
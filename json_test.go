@@ -0,0 +1,176 @@
+package sqlquerybob
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItRoundTripsASelectThroughJSON(t *testing.T) {
+	var d struct {
+		id   int
+		name string
+	}
+	active := NewSelect("users").Select("id").Where("users.active", "=", true)
+	qb := NewSelect("users").
+		ForPostgres().
+		As("u").
+		Select("id", "name").
+		Into(&d.id, &d.name).
+		LeftJoin("teams", "teams.id", "u.team_id").
+		Where("u.name", "LIKE", "a%").
+		WhereGroup(func(gb *Builder) {
+			gb.Where("u.age", "BETWEEN", 18, 30).OrWhereRaw("u.age IS NULL")
+		}).
+		WhereInSubquery("u.id", active).
+		OrderByNullsLast("u.name").
+		Limit(10, 20)
+	expected, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	data, err := json.Marshal(qb)
+	assert.Nil(err)
+
+	var restored Builder
+	assert.Nil(json.Unmarshal(data, &restored))
+	restored.Into(&d.id, &d.name)
+	qry, err := restored.GenerateQuery()
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"a%", 18, 30, true}, restored.Criteria())
+}
+
+func TestItRoundTripsAnUpdateThroughJSON(t *testing.T) {
+	qb := NewUpdate("table1").
+		ForMySQL().
+		Set("field1", "field3").
+		To("value1", Default).
+		SetRaw("field2", "field2 + ?", 1).
+		Where("table1.id", "=", 3)
+	expected, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	data, err := json.Marshal(qb)
+	assert.Nil(err)
+
+	restored := &Builder{}
+	assert.Nil(json.Unmarshal(data, restored))
+	qry, err := restored.GenerateQuery()
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", 1, 3}, restored.Args())
+}
+
+func TestItKeepsTheTypesOfValuesThroughJSON(t *testing.T) {
+	at := time.Date(2024, 3, 9, 14, 5, 7, 123456789, time.UTC)
+	qb := NewSelect("table1").
+		Where("table1.field1", "=", int64(1<<53+1)).
+		Where("table1.field2", "=", []byte("data")).
+		Where("table1.field3", ">", at).
+		Where("table1.field4", "=", uint8(7)).
+		Where("table1.field5", "=", float32(1.5)).
+		Where("table1.field6", "=", nil)
+	data, err := json.Marshal(qb)
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	restored := &Builder{}
+	assert.Nil(json.Unmarshal(data, restored))
+	assert.Equal(qb.Args(), restored.Args())
+	assert.Equal([]any{int64(1<<53 + 1), []byte("data"), at, uint8(7), float32(1.5), nil}, restored.Args())
+}
+
+func TestItKeepsTheValuesOfNamedTypesThroughJSON(t *testing.T) {
+	type userID int64
+	type status string
+	active := status("active")
+	qb := NewSelect("users").
+		Where("users.id", "=", userID(1<<53+1)).
+		Where("users.status", "=", active).
+		Where("users.previous_status", "=", &active).
+		Where("users.name", "=", sql.NullString{String: "name1", Valid: true}).
+		Where("users.deleted_at", "=", sql.NullTime{})
+	data, err := json.Marshal(qb)
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	restored := &Builder{}
+	assert.Nil(json.Unmarshal(data, restored))
+	assert.Equal([]any{int64(1<<53 + 1), "active", "active", "name1", nil}, restored.Args())
+}
+
+func TestItRejectsValuesThatCannotBeRestoredFromJSON(t *testing.T) {
+	qb := NewSelect("table1").Where("table1.field1", "=", struct{ id int }{1})
+	_, err := json.Marshal(qb)
+
+	assert := assert.New(t)
+	assert.ErrorAs(err, &ErrUnsupportedJSONValue{})
+}
+
+func TestItReturnsTheRecordedErrorWhenMarshalingToJSON(t *testing.T) {
+	qb := NewSelect("table1").Where("table1.field1", "==", 1)
+	_, err := json.Marshal(qb)
+
+	assert := assert.New(t)
+	assert.ErrorAs(err, &ErrInvalidSqlOperator{})
+}
+
+func TestItComparesTheLogicalQueryOfBuilders(t *testing.T) {
+	var d1, d2 struct {
+		field1 string
+	}
+	build := func(dest *string, value string) *Builder {
+		return NewSelect("table1").
+			Select("field1").
+			Into(dest).
+			Where("table1.field1", "=", value).
+			OrderBy("field1").
+			Limit(10, 0)
+	}
+
+	assert := assert.New(t)
+	assert.True(build(&d1.field1, "value1").Equal(build(&d2.field1, "value1")))
+	assert.Equal("", build(&d1.field1, "value1").Diff(build(&d2.field1, "value1")))
+
+	qb := build(&d1.field1, "value1")
+	other := build(&d2.field1, "value2").Limit(0, 0)
+	assert.False(qb.Equal(other))
+	assert.Equal(`criteria: [{"column":"table1.field1","operator":"=","values":["value1"]}] != [{"column":"table1.field1","operator":"=","values":["value2"]}]`+"\n"+
+		"hasLimit: true != -\n"+
+		"limit: 10 != -", qb.Diff(other))
+
+	assert.False(qb.Equal(NewSelect("table1").Where("table1.field1", "BETWEEN", 1)))
+}
+
+func TestItComparesBuildersWithValuesThatCannotBeSerialized(t *testing.T) {
+	type status string
+	type point struct{ x, y int }
+	var d1, d2 struct {
+		id int
+	}
+	build := func(dest *int, at point) *Builder {
+		return NewSelect("table1").
+			Select("id").
+			Into(dest).
+			Where("table1.status", "=", status("active")).
+			Where("table1.location", "=", at)
+	}
+
+	assert := assert.New(t)
+	qb := build(&d1.id, point{1, 2})
+	assert.True(qb.Equal(qb))
+	assert.True(qb.Equal(build(&d2.id, point{1, 2})))
+	assert.Equal("", qb.Diff(build(&d2.id, point{1, 2})))
+	assert.False(qb.Equal(build(&d2.id, point{2, 1})))
+	assert.Equal("builders differ, but cannot be serialized to JSON to list the differences:"+
+		" value of type sqlquerybob.point cannot be serialized to JSON", qb.Diff(build(&d2.id, point{2, 1})))
+
+	named := NewSelect("table1").Where("table1.status", "=", status("active"))
+	assert.True(named.Equal(named))
+	assert.True(named.Equal(NewSelect("table1").Where("table1.status", "=", status("active"))))
+}
@@ -0,0 +1,38 @@
+package sqlquerybob
+
+import "sync"
+
+// Pool of builders reused by Acquire and Release
+var builderPool = sync.Pool{
+	New: func() interface{} {
+		return &Builder{}
+	},
+}
+
+// Returns a SELECT builder from a pool of builders, which avoids allocating a new builder
+// for every query in hot paths. The builder has no table and no database engine set, so
+// the table has to be set with From. It should be returned to the pool with Release once
+// its query has been generated and its values are no longer needed.
+func Acquire() *Builder {
+	return builderPool.Get().(*Builder)
+}
+
+// Resets a builder and returns it to the pool of builders used by Acquire. The settings that
+// survive Reset are cleared as well, so the next user of the builder starts from a new
+// SELECT builder. The builder must not be used after it has been released, since it may be
+// handed out again by Acquire at any time.
+func Release(qb *Builder) {
+	qb.Reset()
+	qb.table = ""
+	qb.fromSub = nil
+	qb.alias = ""
+	qb.db = MYSQL
+	qb.queryType = selectQry
+	qb.allowedColumns = nil
+	qb.allowedOperators = nil
+	qb.noAutoPrefix = false
+	qb.emptyInAsFalse = false
+	qb.legacyJoins = false
+	qb.legacyPagination = false
+	builderPool.Put(qb)
+}
@@ -0,0 +1,123 @@
+package sqlquerybob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItResetsABuilder(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	qb := NewSelect("table1").
+		ForPostgres().
+		Select("field1").
+		Into(&d.field1).
+		Join("LEFT", "table2", "table2.table1_id", "table1.id").
+		Where("table1.field1", "==", "value1").
+		OrderBy("table1.field1").
+		Limit(10, 0)
+	qb.Reset()
+
+	var id int
+	qry, err := qb.Select("id").Into(&id).Where("table1.id", "=", 1).GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT table1.id FROM table1 WHERE table1.id=$1", qry)
+	assert.Equal([]any{1}, qb.Criteria())
+}
+
+func TestItKeepsTheSettingsOfABuilderOnReset(t *testing.T) {
+	var id int
+	qb := NewSelect("table1").
+		AllowColumns("id").
+		AllowOperators("@>").
+		EmptyInAsFalse().
+		Select("field1").
+		Where("table1.data", "@>", "{}")
+	qb.Reset()
+
+	assert := assert.New(t)
+	_, err := qb.Select("field1").Into(&id).GenerateQuery()
+	assert.Equal(NewDisallowedColumnError("table1.field1"), err)
+
+	qry, err := qb.Reset().
+		Select("id").
+		Into(&id).
+		Where("table1.id", "@>", "{}").
+		WhereIn("table1.id", nil).
+		GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.id FROM table1 WHERE table1.id@>? AND 1=0", qry)
+}
+
+func TestItReleasesABuilderWithoutLeakingState(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	qb := Acquire().
+		From("table1").
+		ForPostgres().
+		Select("field1").
+		Into(&d.field1).
+		Where("table1.field1", "=", "value1")
+	Release(qb)
+
+	assert := assert.New(t)
+	assert.Equal("", qb.Table())
+	assert.Empty(qb.Columns())
+	assert.Empty(qb.Values())
+	assert.Empty(qb.Criteria())
+	_, err := qb.GenerateQuery()
+	assert.Equal(ErrEmptyTableName, err)
+
+	sub := NewSelect("table2").Select("id").Where("table2.field2", "=", 2)
+	qb = Acquire().
+		FromSubquery(sub, "t2").
+		AllowColumns("id").
+		Select("id")
+	Release(qb)
+	assert.Equal("", qb.Table())
+	assert.Empty(qb.Criteria())
+}
+
+func generateBenchmarkQuery(qb *Builder, d *struct {
+	field1 string
+	field2 int
+}) {
+	qb.Select("field1", "field2").
+		Into(&d.field1, &d.field2).
+		Join("LEFT", "table2", "table2.table1_id", "table1.id").
+		Where("table1.field1", "=", "value1").
+		Where("table1.field2", "IN", 1, 2, 3).
+		OrderBy("table1.field1").
+		Limit(10, 0)
+	qb.GenerateQuery()
+}
+
+func BenchmarkNewBuilder(b *testing.B) {
+	var d struct {
+		field1 string
+		field2 int
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		qb := NewSelect("table1").ForPostgres()
+		generateBenchmarkQuery(qb, &d)
+	}
+}
+
+func BenchmarkPooledBuilder(b *testing.B) {
+	var d struct {
+		field1 string
+		field2 int
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		qb := Acquire().From("table1").ForPostgres()
+		generateBenchmarkQuery(qb, &d)
+		Release(qb)
+	}
+}
@@ -2,6 +2,10 @@ package sqlquerybob
 
 import (
 	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -14,6 +18,7 @@ const (
 	SQLITE
 	POSTGRES
 	ORACLE
+	SQLSERVER
 )
 
 // A custom type that describes the supported query types
@@ -25,8 +30,56 @@ const (
 	insertQry
 	updateQry
 	deleteQry
+	truncateQry
 )
 
+// The names of the database engines, as returned by String and accepted by ParseDatabase
+var databaseNames = map[database]string{
+	MYSQL:     "mysql",
+	SQLITE:    "sqlite",
+	POSTGRES:  "postgres",
+	ORACLE:    "oracle",
+	SQLSERVER: "sqlserver",
+}
+
+// Returns the name of the database engine, like "postgres", for logging and configuration
+func (db database) String() string {
+	if name, ok := databaseNames[db]; ok {
+		return name
+	}
+	return "database(" + strconv.Itoa(int(db)) + ")"
+}
+
+// Returns the database engine with the given name, as returned by String. The name is case
+// insensitive, so "Postgres" and "POSTGRES" are accepted as well, which allows selecting the
+// database engine from a configuration file. Will return ErrUnknownDatabase if no database
+// engine has the name.
+func ParseDatabase(name string) (database, error) {
+	for db, dbName := range databaseNames {
+		if strings.EqualFold(strings.TrimSpace(name), dbName) {
+			return db, nil
+		}
+	}
+	return MYSQL, NewUnknownDatabaseError(name)
+}
+
+// Returns the name of the query type, like "select", for logging
+func (qt queryType) String() string {
+	switch qt {
+	case selectQry:
+		return "select"
+	case insertQry:
+		return "insert"
+	case updateQry:
+		return "update"
+	case deleteQry:
+		return "delete"
+	case truncateQry:
+		return "truncate"
+	}
+	return "queryType(" + strconv.Itoa(int(qt)) + ")"
+}
+
 // A custom type that describes the sort order of a query with ORDER BY
 type sortOrder int8
 
@@ -36,38 +89,214 @@ const (
 	descending
 )
 
-// Valid operators
+// A custom type that describes the placement of NULL values in an ORDER BY
+type nullsOrder int8
+
+// Supported NULL placements
+const (
+	nullsDefault nullsOrder = iota
+	nullsFirst
+	nullsLast
+)
+
+// A custom type that describes the row locking of a SELECT
+type rowLock int8
+
+// Supported row locks
+const (
+	noLock rowLock = iota
+	lockForUpdate
+	lockForShare
+)
+
+// A custom type that describes how a row locking SELECT handles rows locked by others
+type lockWait int8
+
+// Supported lock waiting behaviours
+const (
+	waitLocked lockWait = iota
+	skipLocked
+	noWait
+)
+
+// A custom type that describes how the placeholders of a comparison operator are generated
+type placeholderTemplate int8
+
+// Supported placeholder templates
 const (
-	validOperators = "=/>/</>=/<=/<>/IN/BETWEEN/LIKE"
+	singlePlaceholder placeholderTemplate = iota
+	rangePlaceholders
+	listPlaceholders
 )
 
+// The rendering of a comparison operator. A spaced operator is separated from the column and
+// the placeholders with spaces, like column LIKE ?, while the others are not, like column=?.
+// The placeholders are generated as
+//   - ? for a single placeholder
+//   - ? AND ? for range placeholders
+//   - (?,?,...) for list placeholders, one for every value
+type operatorSpec struct {
+	spaced       bool
+	placeholders placeholderTemplate
+}
+
+// Valid operators
+var operators = map[string]operatorSpec{
+	"=":           {spaced: false, placeholders: singlePlaceholder},
+	">":           {spaced: false, placeholders: singlePlaceholder},
+	"<":           {spaced: false, placeholders: singlePlaceholder},
+	">=":          {spaced: false, placeholders: singlePlaceholder},
+	"<=":          {spaced: false, placeholders: singlePlaceholder},
+	"<>":          {spaced: false, placeholders: singlePlaceholder},
+	"IN":          {spaced: true, placeholders: listPlaceholders},
+	"NOT IN":      {spaced: true, placeholders: listPlaceholders},
+	"BETWEEN":     {spaced: true, placeholders: rangePlaceholders},
+	"NOT BETWEEN": {spaced: true, placeholders: rangePlaceholders},
+	"LIKE":        {spaced: true, placeholders: singlePlaceholder},
+	"NOT LIKE":    {spaced: true, placeholders: singlePlaceholder},
+	"ILIKE":       {spaced: true, placeholders: singlePlaceholder},
+}
+
+// A single condition of a WHERE clause. A criterion with a group holds a nested list of
+// criteria that is wrapped in parentheses when the query is generated. A raw criterion
+// holds a literal SQL fragment in column. A criterion with a sub compares against the
+// result of a subquery instead of values. A criterion with escape is a LIKE that uses \
+// as its escape character. A criterion with a quantifier (ANY, ALL) compares against the
+// elements of a single array value.
+type criterion struct {
+	column     string
+	operator   string
+	values     []interface{}
+	or         bool
+	group      []criterion
+	raw        bool
+	sub        *Builder
+	escape     bool
+	quantifier string
+	literal    bool
+	rowColumns []string
+	json       bool
+	jsonKey    string
+	column2    string
+}
+
+// A joined table. Joins are generated with an ON column=fkey clause, an ON clause with a
+// literal SQL condition and the values bound to its placeholders, a USING clause when using
+// holds columns, or without a condition when none is set, like CROSS JOIN. A joined table
+// with an alias is generated as table alias.
+type join struct {
+	joinType  string
+	table     string
+	alias     string
+	column    string
+	fkey      string
+	condition string
+	values    []interface{}
+	using     []string
+}
+
+// A column of the ORDER BY clause. A raw ordering holds a literal SQL expression in
+// column that is generated without a direction. An ordinal ordering holds the position
+// of a selected column in column.
+type ordering struct {
+	column    string
+	direction sortOrder
+	nulls     nullsOrder
+	raw       bool
+	ordinal   bool
+	values    []interface{}
+}
+
+// A MySQL index hint of the table of the query, like USE INDEX (index)
+type indexHint struct {
+	hint  string
+	index string
+}
+
+// A comparison of a column, as defined with WhereAllOf and WhereAnyOf
+type Condition struct {
+	Column   string
+	Operator string
+	Values   []interface{}
+}
+
+// The order on a column, as defined with OrderByMany
+type OrderSpec struct {
+	Column     string
+	Descending bool
+}
+
+// Returns the joined table followed by its alias, if any
+func (j join) tableWithAlias() string {
+	if j.alias == "" {
+		return j.table
+	}
+	return j.table + " " + j.alias
+}
+
+// A query combined with the results of the builder by Union or UnionAll
+type union struct {
+	all bool
+	sub *Builder
+}
+
+// A function selected with SelectStringAgg, SelectGreatest, SelectLeast or
+// SelectCoalesceDefault, which has a different name or syntax for some database engines or
+// binds values, so it is generated for the database engine of the query
+type selectedFunction struct {
+	name      string
+	columns   []string
+	separator string
+	values    []interface{}
+}
+
+// A common table expression defined with With
+type cte struct {
+	name string
+	sub  *Builder
+}
+
 type Builder struct {
 	db               database
 	placeholderCount int
 	queryType        queryType
+	ctes             []cte
 	table            string
-	joinTables       []struct {
-		joinType string
-		table    string
-		column   string
-		fkey     string
-	}
+	fromSub          *Builder
+	alias            string
+	indexHints       []indexHint
+	joinTables       []join
+	distinct         bool
+	distinctOn       []string
 	columns          []string
+	selectSubqueries map[string]*Builder
+	selectFunctions  map[string]selectedFunction
 	returningColumns []string
+	deleteTables     []string
 	values           []interface{}
 	returnValues     []interface{}
-	criteria         []struct {
-		column   string
-		operator string
-		values   []interface{}
-		or       bool
-	}
-	orderBy []struct {
-		column    string
-		direction sortOrder
-	}
-	limit  uint
-	offset uint
+	criteria         []criterion
+	groupBy          []string
+	having           []criterion
+	unions           []union
+	orderBy          []ordering
+	limit            uint
+	hasLimit         bool
+	offset           uint
+	withTies         bool
+	lock             rowLock
+	lockWait         lockWait
+	lastInsertID     bool
+	legacyJoins      bool
+	legacyPagination bool
+	noAutoPrefix     bool
+	allowedColumns   []string
+	allowedOperators []string
+	expressions      map[string][]string
+	emptyInAsFalse   bool
+	selectAll        bool
+	returningAll     bool
+	err              error
 }
 
 // Creates a new query builder for SELECT. The table on which we are going
@@ -79,6 +308,152 @@ func NewSelect(tableName string) *Builder {
 	}
 }
 
+// Returns the first error that has been recorded while defining the query, or nil. Errors
+// that depend on the whole query, like the database engine, are only returned by
+// GenerateQuery.
+func (qb *Builder) Err() error {
+	return qb.err
+}
+
+// Records an error of the builder. Only the first error is kept since the following ones
+// are usually caused by it
+func (qb *Builder) addError(err error) {
+	if qb.err == nil {
+		qb.err = err
+	}
+}
+
+// Returns an ErrUnsupportedForDialect for a feature the database engine of the builder does
+// not support, wrapping the error specific to the feature
+func (qb *Builder) unsupported(feature string, err error) error {
+	return NewUnsupportedForDialectError(feature, qb.db, err)
+}
+
+// Records an error if the number of ? of a raw SQL fragment is not equal to the number of
+// values bound to them
+func (qb *Builder) checkPlaceholders(fragment string, values []interface{}) {
+	if count := strings.Count(fragment, "?"); count != len(values) {
+		qb.addError(NewBadPlaceholdersValuesComboError(count, len(values)))
+	}
+}
+
+// Clears the query defined on the builder, so it can be reused to build a new query on the
+// same table. The settings of the builder survive, which are its query type, database
+// engine, table, FROM subquery and alias, the columns and operators allowed with
+// AllowColumns and AllowOperators, and the flags set with DisableAutoPrefix,
+// EmptyInAsFalse, LegacyOracleJoins and OracleLegacyPagination. Everything else is cleared,
+// including a recorded error. The allocated memory of the columns, values, criteria, joins
+// and order is kept.
+func (qb *Builder) Reset() *Builder {
+	qb.placeholderCount = 0
+	qb.ctes = nil
+	qb.indexHints = nil
+	qb.joinTables = qb.joinTables[:0]
+	qb.distinct = false
+	qb.distinctOn = nil
+	qb.columns = qb.columns[:0]
+	qb.selectSubqueries = nil
+	qb.selectFunctions = nil
+	qb.returningColumns = nil
+	qb.deleteTables = nil
+	qb.values = qb.values[:0]
+	qb.returnValues = nil
+	qb.criteria = qb.criteria[:0]
+	qb.groupBy = nil
+	qb.having = nil
+	qb.unions = nil
+	qb.orderBy = qb.orderBy[:0]
+	qb.limit, qb.hasLimit, qb.offset, qb.withTies = 0, false, 0, false
+	qb.lock, qb.lockWait = noLock, waitLocked
+	qb.lastInsertID = false
+	qb.expressions = nil
+	qb.selectAll = false
+	qb.returningAll = false
+	qb.err = nil
+	return qb
+}
+
+// Hints MySQL to use an index of the table of the query to find its rows, with USE INDEX
+// (index) after the table. Index hints are only supported by MySQL, so generating the query
+// for any other database engine will return an error.
+func (qb *Builder) UseIndex(index string) *Builder {
+	qb.indexHints = append(qb.indexHints, indexHint{hint: "USE INDEX", index: index})
+	return qb
+}
+
+// Forces MySQL to use an index of the table of the query to find its rows, with FORCE INDEX
+// (index) after the table. The same rules as in UseIndex apply.
+func (qb *Builder) ForceIndex(index string) *Builder {
+	qb.indexHints = append(qb.indexHints, indexHint{hint: "FORCE INDEX", index: index})
+	return qb
+}
+
+// Stops prefixing the columns without a table with the table of the query, so Select,
+// Returning, OrderBy and the other methods that take columns use them as they are. This is
+// needed when the table is not a real prefix, like an aliased derived table. Since columns
+// are prefixed when they are added, this should be called before adding them.
+func (qb *Builder) DisableAutoPrefix() *Builder {
+	qb.noAutoPrefix = true
+	return qb
+}
+
+// Sets the table of the query to a template with the identifier substituted for {ident},
+// which allows choosing a schema or a shard at runtime, like
+//   - TableTemplate("tenant_{ident}.events", tenantID) will use the table tenant_42.events
+//
+// Table names cannot be bound to placeholders, so the identifier may only have letters,
+// digits and underscores, which makes it safe to derive it from user input. Will record
+// ErrInvalidIdentifier otherwise. The same rules as in From apply.
+func (qb *Builder) TableTemplate(format, ident string) *Builder {
+	if ident == "" || strings.Trim(ident, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_") != "" {
+		qb.addError(NewInvalidIdentifierError(ident))
+	}
+	return qb.From(strings.ReplaceAll(format, "{ident}", ident))
+}
+
+// Sets the FROM of a select to a derived table, which is a subquery with an alias, like
+//   - FromSubquery(NewSelect("orders").Select("user_id").Where("orders.total", ">", 100), "big")
+//     will produce FROM (SELECT orders.user_id FROM orders WHERE orders.total>?) big
+//
+// The alias takes the place of the table, so columns without a table are prefixed with it.
+// The subquery must be a SELECT builder. It is generated for the database engine of this
+// builder, continuing its placeholder numbering, and its criteria values come before the
+// values of the joins and the WHERE clause in Criteria. The same rules as in From apply.
+func (qb *Builder) FromSubquery(sub *Builder, alias string) *Builder {
+	qb.From(alias)
+	qb.fromSub = sub
+	return qb
+}
+
+// Sets the table of the query, replacing the one passed to the constructor. Columns are
+// prefixed with the table when they are added, so From should be called before Select and
+// the other methods that take columns, since the columns already added keep their table.
+func (qb *Builder) From(tableName string) *Builder {
+	qb.table = tableName
+	qb.fromSub = nil
+	return qb
+}
+
+// Sets an alias for the table of the query, which is generated as FROM table alias. The
+// alias is used instead of the table to prefix the columns without a table, so As should
+// be called before Select and the other methods that take columns, like From. For example
+//   - NewSelect("users").As("u").Select("name") will produce SELECT u.name FROM users u
+//
+// The INSERT clause has no alias, so the columns of an insert keep being prefixed with the
+// table.
+func (qb *Builder) As(alias string) *Builder {
+	qb.alias = alias
+	return qb
+}
+
+// Returns the table of the query followed by its alias, if any
+func (qb *Builder) tableWithAlias() string {
+	if qb.alias == "" {
+		return qb.table
+	}
+	return qb.table + " " + qb.alias
+}
+
 // Sets the database engine the queries will be produced for
 func (qb *Builder) ForDatabase(db database) *Builder {
 	qb.db = db
@@ -105,47 +480,434 @@ func (qb *Builder) ForSQLite() *Builder {
 	return qb.ForDatabase(SQLITE)
 }
 
+// Sets the database engine for SQL Server
+func (qb *Builder) ForSQLServer() *Builder {
+	return qb.ForDatabase(SQLSERVER)
+}
+
+// Makes an IN criterion without values generate the always false predicate 1=0 instead
+// of returning ErrEmptyInValues. This is useful when the IN values come from a slice
+// that may be empty, in which case no rows should match. Likewise, a NOT IN criterion
+// without values generates the always true predicate 1=1.
+func (qb *Builder) EmptyInAsFalse() *Builder {
+	qb.emptyInAsFalse = true
+	return qb
+}
+
+// Define a common table expression that will be prepended to the query as
+// WITH name AS (subquery). Multiple common table expressions can be added by chaining
+// this, and they are generated in the order they were defined. The subquery must be a
+// SELECT builder and is generated for the database engine of this builder, continuing its
+// placeholder numbering, so its criteria values come first in Criteria(). Note that MySQL
+// supports common table expressions only since 8.0 and SQLite since 3.8.3.
+func (qb *Builder) With(name string, sub *Builder) *Builder {
+	qb.ctes = append(qb.ctes, cte{name: name, sub: sub})
+	return qb
+}
+
 // Define the table columns to be selected. Table columns can be added by their name
 // or prefixed by their table name. If a table name is not prefixed, the table that has
 // been defined in NewSelect will be prefixed. For example
 //   - NewSelect("table1").Select("column1", "table2.column5") will store the columns as
 //     table1.column1, table2.column5
+//   - NewSelect("schema1.table1").Select("column1", "schema2.table2.column5") will store the
+//     columns as schema1.table1.column1, schema2.table2.column5
+//
+// Function expressions, like COUNT(*) or LOWER(column1), are stored as they are, so the
+// columns inside them have to be prefixed by the caller when needed. A column can have an
+// alias, like "column1 AS c1", and quoted identifiers, like table1."order", are
+// accepted. Other expressions, like COUNT(DISTINCT column1), have to be selected with
+// SelectRaw.
 func (qb *Builder) Select(columns ...string) *Builder {
 	for _, column := range columns {
-		tableColumn := strings.Split(column, ".")
-		if len(tableColumn) == 1 {
-			qb.columns = append(qb.columns, qb.table+"."+column)
+		qb.columns = append(qb.columns, qb.prefixColumn(column))
+	}
+	return qb
+}
+
+// Define a literal SQL expression to be selected, like COUNT(DISTINCT table1.column1) AS c.
+// The expression is not prefixed and its identifiers are not validated, so it must never
+// contain untrusted input. It counts as a single column, so it must be paired with a single
+// value in Into, and it is not checked against the columns allowed with AllowColumns.
+func (qb *Builder) SelectRaw(expression string) *Builder {
+	qb.columns = append(qb.columns, expression)
+	qb.addExpression(expression)
+	return qb
+}
+
+// Sets the columns to be selected, replacing the ones defined before, for a query that only
+// needs its SQL and args, like one passed to a different execution layer. The columns are
+// prefixed like in Select. Since there are no destinations to scan the results into, the
+// values defined with Into are cleared and Values() returns nil, and the number of values
+// is not checked against the columns. Note that the number of values is only checked when
+// at least one value has been defined with Into, for Select as well.
+func (qb *Builder) SelectColumns(columns ...string) *Builder {
+	qb.columns = qb.columns[:0]
+	qb.values = nil
+	return qb.Select(columns...)
+}
+
+// Selects only distinct rows with SELECT DISTINCT
+func (qb *Builder) Distinct() *Builder {
+	qb.distinct = true
+	return qb
+}
+
+// Selects only the first row of each set of rows that have equal values in the columns with
+// SELECT DISTINCT ON (column1,column2,...). The first row of each set is defined by the
+// ORDER BY clause, which has to start with the same columns. Columns without a table are
+// prefixed like in Select. DISTINCT ON is supported only by PostgreSQL, so generating the
+// query for any other database engine will return an error, as will combining it with
+// Distinct.
+func (qb *Builder) DistinctOn(columns ...string) *Builder {
+	for _, column := range columns {
+		qb.distinctOn = append(qb.distinctOn, qb.prefixColumn(column))
+	}
+	return qb
+}
+
+// Selects all columns with SELECT *. Since there are no columns to match, the number of
+// values is not checked against the columns, so scanning the rows into the values passed
+// to Into is the responsibility of the caller.
+func (qb *Builder) SelectAll() *Builder {
+	qb.columns = append(qb.columns, "*")
+	qb.addExpression("*")
+	qb.selectAll = true
+	return qb
+}
+
+// Define a COALESCE(column1,column2,...) AS alias column to be selected. Columns without a
+// table are prefixed like in Select, while numeric literals, like 0, are kept as they are.
+// The expression counts as a single column, so it must be paired with a single value in
+// Into, like
+//   - SelectCoalesce("name", "nickname", "first_name").Into(&name)
+//
+// Use SelectCoalesceDefault for a fallback value of any other type.
+func (qb *Builder) SelectCoalesce(alias string, columns ...string) *Builder {
+	return qb.selectFunction("COALESCE", alias, columns...)
+}
+
+// Define a COALESCE(column1,column2,...,?) AS alias column to be selected, with the fallback
+// value bound to the last placeholder, like
+//   - SelectCoalesceDefault("name", "n/a", "nickname", "first_name") will produce
+//     COALESCE(table1.nickname,table1.first_name,?) AS name
+//
+// The fallback value comes before the values of the other clauses in Criteria, in the
+// position of the column. The same rules as in SelectCoalesce apply.
+func (qb *Builder) SelectCoalesceDefault(alias string, fallback interface{}, columns ...string) *Builder {
+	arguments, sources := qb.functionArguments(columns)
+	function := selectedFunction{name: "COALESCE", columns: arguments, values: []interface{}{fallback}}
+	return qb.selectDialectFunction(function, alias, sources)
+}
+
+// Define a NULLIF(column1,column2) AS alias column to be selected, which is NULL if the two
+// columns are equal and column1 otherwise. The same rules as in SelectCoalesce apply.
+func (qb *Builder) SelectNullIf(alias, column1, column2 string) *Builder {
+	return qb.selectFunction("NULLIF", alias, column1, column2)
+}
+
+// Define a COUNT(DISTINCT column) AS alias column to be selected, which counts the distinct
+// non NULL values of the column. The same rules as in SelectCoalesce apply, and like any
+// aggregate, the other selected columns have to be grouped with GROUP BY.
+func (qb *Builder) CountDistinct(column, alias string) *Builder {
+	column = qb.prefixColumn(column)
+	return qb.selectExpression("COUNT(DISTINCT "+column+")", alias, column)
+}
+
+// Define a string aggregation of a column AS alias to be selected, which concatenates the
+// values of the column in each group with the separator. The aggregation is generated for
+// the database engine of the builder when the query is generated, as
+//   - GROUP_CONCAT(column SEPARATOR separator) for MySQL
+//   - GROUP_CONCAT(column,separator) for SQLite
+//   - STRING_AGG(column,separator) for PostgreSQL and SQL Server
+//   - LISTAGG(column,separator) for Oracle
+//
+// The column is prefixed like in Select. Like any aggregate, the other selected columns
+// have to be grouped with GROUP BY.
+func (qb *Builder) SelectStringAgg(column, separator, alias string) *Builder {
+	column = qb.prefixColumn(column)
+	function := selectedFunction{name: "STRING_AGG", columns: []string{column}, separator: separator}
+	return qb.selectDialectFunction(function, alias, function.columns)
+}
+
+// Define a GREATEST(column1,column2,...) AS alias column to be selected, which is the
+// largest value of the columns in each row. SQLite has no GREATEST, so its scalar
+// MAX(column1,column2,...) is generated instead when the query is generated for SQLite.
+// SQL Server does not support GREATEST before SQL Server 2022, so generating the query for
+// it will return ErrDBEngineDoesNotSupportGreatestLeast. The same rules as in
+// SelectCoalesce apply.
+func (qb *Builder) SelectGreatest(alias string, columns ...string) *Builder {
+	return qb.selectGreatestLeast("GREATEST", alias, columns...)
+}
+
+// Define a LEAST(column1,column2,...) AS alias column to be selected, which is the smallest
+// value of the columns in each row. SQLite's scalar MIN(column1,column2,...) is generated
+// instead for SQLite. The same rules as in SelectGreatest apply.
+func (qb *Builder) SelectLeast(alias string, columns ...string) *Builder {
+	return qb.selectGreatestLeast("LEAST", alias, columns...)
+}
+
+func (qb *Builder) selectGreatestLeast(function, alias string, columns ...string) *Builder {
+	arguments, sources := qb.functionArguments(columns)
+	return qb.selectDialectFunction(selectedFunction{name: function, columns: arguments}, alias, sources)
+}
+
+// Adds a function that differs between database engines or binds values to the selected
+// columns, recording the columns it is computed from. It is stored in its PostgreSQL form,
+// with ? for its values, as returned by Columns, and generated for the database engine of
+// the query by generateSelectedFunction.
+func (qb *Builder) selectDialectFunction(function selectedFunction, alias string, sources []string) *Builder {
+	expression := function.name + "(" + strings.Join(function.columns, ",")
+	if function.name == "STRING_AGG" {
+		expression += ",'" + strings.ReplaceAll(function.separator, "'", "''") + "'"
+	}
+	for range function.values {
+		expression += ",?"
+	}
+	qb.selectExpression(expression+")", alias, sources...)
+	if qb.selectFunctions == nil {
+		qb.selectFunctions = make(map[string]selectedFunction)
+	}
+	qb.selectFunctions[qb.columns[len(qb.columns)-1]] = function
+	return qb
+}
+
+// Adds a function(column1,column2,...) AS alias column to the selected columns, prefixing
+// the columns without a table
+func (qb *Builder) selectFunction(function, alias string, columns ...string) *Builder {
+	arguments, sources := qb.functionArguments(columns)
+	return qb.selectExpression(function+"("+strings.Join(arguments, ",")+")", alias, sources...)
+}
+
+// Prefixes the arguments of a selected function like in Select, except numeric literals,
+// like 0 or 1.5, which are kept as they are. Returns the arguments and the columns among
+// them.
+func (qb *Builder) functionArguments(arguments []string) ([]string, []string) {
+	prefixed := make([]string, len(arguments))
+	var columns []string
+	for i, argument := range arguments {
+		if isNumericLiteral(argument) {
+			prefixed[i] = argument
+			continue
 		}
-		if len(tableColumn) == 2 {
-			qb.columns = append(qb.columns, column)
+		prefixed[i] = qb.prefixColumn(argument)
+		columns = append(columns, prefixed[i])
+	}
+	return prefixed, columns
+}
+
+// Checks if a string is a numeric literal, made of digits with an optional leading minus
+// and a single decimal point
+func isNumericLiteral(value string) bool {
+	value = strings.TrimPrefix(value, "-")
+	digits, point := 0, false
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '.' && !point:
+			point = true
+		default:
+			return false
 		}
 	}
+	return digits > 0
+}
+
+// Define a subquery to be selected as a column with an alias, like
+//   - SelectSubquery(NewSelect("orders").Select("COUNT(*)").WhereRaw("orders.user_id=users.id"), "order_count")
+//     will produce SELECT (SELECT COUNT(*) FROM orders WHERE orders.user_id=users.id) AS order_count
+//
+// The subquery must be a SELECT builder that selects a single value. It is generated for
+// the database engine of this builder, continuing its placeholder numbering, and its
+// criteria values come before the values of the other clauses in Criteria. As with the
+// other selected expressions, a value must be passed to Into for it. Each subquery must
+// have its own alias, or ErrDuplicateAlias is recorded.
+func (qb *Builder) SelectSubquery(sub *Builder, alias string) *Builder {
+	if qb.selectSubqueries == nil {
+		qb.selectSubqueries = make(map[string]*Builder)
+	}
+	if _, ok := qb.selectSubqueries[selectedSubquery+" AS "+alias]; ok {
+		qb.addError(NewDuplicateAliasError(alias))
+	}
+	qb.selectExpression(selectedSubquery, alias)
+	qb.selectSubqueries[qb.columns[len(qb.columns)-1]] = sub
+	return qb
+}
+
+// The selected column of a subquery until the subquery is generated
+const selectedSubquery = "(subquery)"
+
+// Adds an expression AS alias column to the selected columns, recording the columns it is
+// computed from
+func (qb *Builder) selectExpression(expression, alias string, columns ...string) *Builder {
+	if !isValidIdentifier(alias) {
+		qb.addError(NewInvalidIdentifierError(alias))
+	}
+	expression += " AS " + alias
+	qb.columns = append(qb.columns, expression)
+	qb.addExpression(expression, columns...)
 	return qb
 }
 
-// Define the table columns to be returned from an insert.
+// Quotes a string as an SQL string literal of the database engine, doubling the single
+// quotes, and the backslashes for MySQL which treats them as escape characters
+func (qb *Builder) quoteString(value string) string {
+	if qb.db == MYSQL {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// Records a selected expression together with the columns it is computed from, which are
+// checked instead of the expression itself against the columns allowed with AllowColumns
+func (qb *Builder) addExpression(expression string, columns ...string) {
+	if qb.expressions == nil {
+		qb.expressions = make(map[string][]string)
+	}
+	qb.expressions[expression] = columns
+}
+
+// Define the table columns to be returned from an insert, update or delete. RETURNING is
+// supported by PostgreSQL, Oracle and SQLite (since 3.35). The returned columns can be used
+// in two ways
+//   - with Into, which must be passed a value for every returned column, as with a select
+//   - without Into, to only generate the query and scan the returned rows manually, in which
+//     case the number of values is not checked
 func (qb *Builder) Returning(columns ...string) *Builder {
 	for _, column := range columns {
-		tableColumn := strings.Split(column, ".")
-		if len(tableColumn) == 1 {
-			qb.returningColumns = append(qb.returningColumns, qb.table+"."+column)
-		}
-		if len(tableColumn) == 2 {
-			qb.returningColumns = append(qb.returningColumns, column)
-		}
+		qb.returningColumns = append(qb.returningColumns, qb.prefixColumn(column))
+	}
+	return qb
+}
+
+// Returns all the columns of the rows of an insert, update or delete with RETURNING *. The
+// number of returned columns depends on the table, so the number of values passed to Into
+// is not checked against them, and scanning the rows into the values passed to Into is the
+// responsibility of the caller, as with SelectAll. The same database engines as in
+// Returning are supported.
+func (qb *Builder) ReturningAll() *Builder {
+	qb.returningColumns = append(qb.returningColumns, "*")
+	qb.returningAll = true
+	return qb
+}
+
+// Prefixes a column with the table of the builder. Columns that are already qualified,
+// like table.column or schema.table.column, and function expressions, like COUNT(*), are
+// returned as they are. The table of the builder may itself be schema qualified, like
+// schema.table. Columns are returned as they are as well if DisableAutoPrefix has been called.
+// The alias set with As is used instead of the table, except for inserts, which do not
+// generate it.
+func (qb *Builder) prefixColumn(column string) string {
+	if qb.noAutoPrefix || strings.Contains(column, ".") || strings.Contains(column, "(") {
+		return column
+	}
+	if qb.alias != "" && qb.queryType != insertQry {
+		return qb.alias + "." + column
+	}
+	return qb.table + "." + column
+}
+
+// Restricts the columns that can be used in the query to the given ones, which makes it
+// safe to build queries from column names provided by users, like sort or filter fields.
+// Columns without a table are prefixed like in Select, so "column1" and "table1.column1"
+// are the same column. Generating a query that uses any other column in its selected,
+// returning, set, join, WHERE or ORDER BY columns will return ErrDisallowedColumn. Raw
+// criteria and orders are not checked. Multiple calls add to the allowed columns.
+func (qb *Builder) AllowColumns(columns ...string) *Builder {
+	qb.allowedColumns = append(qb.allowedColumns, columns...)
+	return qb
+}
+
+// Adds operators to the ones accepted by Where and the other comparison methods, like the
+// PostgreSQL @> (contains) or ~ (regular expression match). The added operators are
+// generated like =, with a single placeholder and no spaces, as in table1.data@>?, except
+// word operators, like SIMILAR TO, which are separated with spaces, as in
+// table1.name SIMILAR TO ?. Since
+// operators are validated when a criterion is defined, this must be called before using
+// them. Multiple calls add to the allowed operators.
+func (qb *Builder) AllowOperators(operators ...string) *Builder {
+	for _, operator := range operators {
+		qb.allowedOperators = append(qb.allowedOperators, normalizeOperator(operator))
 	}
 	return qb
 }
 
+// Locks the selected rows for update with FOR UPDATE. FOR UPDATE is supported by MySQL,
+// PostgreSQL and Oracle, so generating the query for any other database engine will return
+// an error. SQL Server uses table hints for locking instead.
+func (qb *Builder) ForUpdate() *Builder {
+	qb.lock = lockForUpdate
+	return qb
+}
+
+// Locks the selected rows in share mode with FOR SHARE. FOR SHARE is supported by MySQL
+// and PostgreSQL, so generating the query for any other database engine will return an
+// error.
+func (qb *Builder) ForShare() *Builder {
+	qb.lock = lockForShare
+	return qb
+}
+
+// Makes a locking select skip the rows that are locked by other transactions instead of
+// waiting for them, with SKIP LOCKED.
+func (qb *Builder) SkipLocked() *Builder {
+	qb.lockWait = skipLocked
+	return qb
+}
+
+// Makes a locking select fail immediately if a row is locked by another transaction
+// instead of waiting for it, with NOWAIT.
+func (qb *Builder) NoWait() *Builder {
+	qb.lockWait = noWait
+	return qb
+}
+
 // Adds a limit and / or offset clause to the query. If offset is not required, pass 0 as the
 // offset argument. Limit and offset must be non negative integers so we avoid this error by
-// making they are uints.
+// making they are uints. A limit of 0 means no limit, use LimitZero for a LIMIT 0. An
+// offset without a limit is almost always a pagination mistake, so generating the query
+// will return ErrOffsetWithoutLimit for it.
 func (qb *Builder) Limit(limit, offset uint) *Builder {
 	qb.limit = limit
+	qb.hasLimit = limit > 0
 	qb.offset = offset
 	return qb
 }
 
+// Adds a LIMIT 0 clause to the query, which returns no rows and is useful to get the
+// columns of a query without its results. The offset is left unchanged, so it is still
+// generated after the LIMIT 0, as in LIMIT 0 OFFSET 20. SQL Server does
+// not allow fetching zero rows, so generating the query for it will return an error.
+func (qb *Builder) LimitZero() *Builder {
+	qb.limit = 0
+	qb.hasLimit = true
+	return qb
+}
+
+// Makes the limit of the query include the rows that tie with the last row in the order,
+// with FETCH FIRST n ROWS WITH TIES, so the query may return more rows than the limit. WITH
+// TIES is supported by PostgreSQL and Oracle, so generating the query for any other
+// database engine will return an error, as will generating a query without an ORDER BY or
+// a limit.
+func (qb *Builder) WithTies() *Builder {
+	qb.withTies = true
+	return qb
+}
+
+// Paginates the query by setting the limit to pageSize and the offset to the rows of the
+// previous pages. Pages start at 1, so page 0 is treated as the first page. Will record
+// ErrPageOutOfRange if the offset of the page overflows.
+func (qb *Builder) Page(page, pageSize uint) *Builder {
+	if page == 0 {
+		page = 1
+	}
+	if pageSize > 0 && page-1 > math.MaxUint/pageSize {
+		qb.addError(ErrPageOutOfRange)
+	}
+	return qb.Limit(pageSize, (page-1)*pageSize)
+}
+
 func (qb *Builder) Set(columns ...string) *Builder {
 	qb.columns = append(qb.columns, columns...)
 	return qb
@@ -156,8 +918,159 @@ func (qb *Builder) To(values ...interface{}) *Builder {
 	return qb
 }
 
+// An SQL keyword that is generated in place of an insert / update value, without a
+// placeholder
+type sqlKeyword string
+
+// Makes a column of an insert / update take its default value, by generating the DEFAULT
+// keyword instead of a placeholder, like
+//   - Set("field1", "created_at").To("value1", Default) will produce
+//     INSERT INTO table1 (field1,created_at) VALUES (?,DEFAULT)
+//
+// The column still counts as a column with a value, but no value is bound for it. SQLite
+// does not support DEFAULT values, so generating the query for it will return an error.
+const Default = sqlKeyword("DEFAULT")
+
+// Makes a column of an insert / update take the current timestamp of the database, like
+//   - Set("updated_at").To(CurrentTimestamp) will produce UPDATE table1 SET updated_at=NOW()
+//
+// The function is generated for the database engine of the builder, as NOW() for MySQL,
+// now() for PostgreSQL, SYSDATE for Oracle and CURRENT_TIMESTAMP for SQLite and SQL Server.
+// No value is bound for it.
+const CurrentTimestamp = sqlKeyword("CURRENT_TIMESTAMP")
+
+// A literal SQL expression assigned to a column by SetRaw, with the values bound to its
+// placeholders
+type rawExpression struct {
+	expression string
+	values     []interface{}
+}
+
+// Define a column that is assigned a literal SQL expression instead of a value. Each ? in
+// the expression is replaced by a placeholder of the database engine and the values are
+// bound to them in order. For example
+//   - NewUpdate("table1").SetRaw("views", "views+?", 1) will produce UPDATE table1 SET views=views+?
+//
+// The expression is not escaped or validated, so it must never contain untrusted input.
+func (qb *Builder) SetRaw(column, expression string, values ...interface{}) *Builder {
+	qb.checkPlaceholders(expression, values)
+	qb.columns = append(qb.columns, column)
+	qb.values = append(qb.values, rawExpression{expression: expression, values: values})
+	return qb
+}
+
+// Marks rows as deleted by setting a column of an update, like deleted_at, to the current
+// timestamp of the database, instead of deleting them. It is meant to be combined with
+// Where, like
+//   - NewUpdate("users").SoftDelete("deleted_at").Where("users.id", "=", 1) will produce
+//     UPDATE users SET deleted_at=NOW() WHERE users.id=?
+//
+// The timestamp is generated like CurrentTimestamp. Will record ErrNotUpdateQuery if the
+// query is not an update.
+func (qb *Builder) SoftDelete(column string) *Builder {
+	if qb.queryType != updateQry {
+		qb.addError(ErrNotUpdateQuery)
+	}
+	qb.columns = append(qb.columns, column)
+	qb.values = append(qb.values, CurrentTimestamp)
+	return qb
+}
+
+// Define a column of an update that is assigned a different value for every value of a key
+// column, which updates many rows with a single query, like
+//   - SetCase("price", "id", map[interface{}]interface{}{1: 10, 2: 20}) will produce
+//     SET price=CASE id WHEN ? THEN ? WHEN ? THEN ? ELSE price END
+//
+// The rows whose key is not in the mapping keep their value. The WHEN clauses are sorted by
+// key, so the same mapping always produces the same query and values. Restrict the updated
+// rows with a WhereIn on the key column to avoid scanning the whole table. Will record
+// ErrEmptyCaseMapping if the mapping is empty.
+func (qb *Builder) SetCase(column, keyColumn string, mapping map[interface{}]interface{}) *Builder {
+	if len(mapping) == 0 {
+		qb.addError(ErrEmptyCaseMapping)
+	}
+	if !isValidIdentifier(keyColumn) {
+		qb.addError(NewInvalidIdentifierError(keyColumn))
+	}
+	keys := make([]interface{}, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return lessKey(keys[i], keys[j])
+	})
+	expression := "CASE " + keyColumn
+	values := make([]interface{}, 0, 2*len(keys))
+	for _, key := range keys {
+		expression += " WHEN ? THEN ?"
+		values = append(values, key, mapping[key])
+	}
+	expression += " ELSE " + column + " END"
+	return qb.SetRaw(column, expression, values...)
+}
+
+// Orders the keys of a SetCase mapping. Numbers come first, then strings and then the keys
+// of any other type. Numbers are compared by value, and numbers with the same value, like
+// int 1 and float64 1, by their type. Strings are compared alphabetically, and the other
+// keys by their type and text, which is arbitrary but a total order, so the same mapping
+// always produces the same query.
+func lessKey(a, b interface{}) bool {
+	if rankA, rankB := keyRank(a), keyRank(b); rankA != rankB {
+		return rankA < rankB
+	}
+	if x, ok := a.(string); ok {
+		return x < b.(string)
+	}
+	if lessNumber(a, b) || lessNumber(b, a) {
+		return lessNumber(a, b)
+	}
+	return fmt.Sprintf("%T%v", a, a) < fmt.Sprintf("%T%v", b, b)
+}
+
+// Returns the rank of the kind of a SetCase key, which are ordered numbers first, then
+// strings, then any other type
+func keyRank(key interface{}) int {
+	if _, ok := toFloat(key); ok {
+		return 0
+	}
+	if _, ok := key.(string); ok {
+		return 1
+	}
+	return 2
+}
+
+// Checks if the number a is less than the number b. Integers of the same signedness are
+// compared exactly, the other numbers as float64.
+func lessNumber(a, b interface{}) bool {
+	x, y := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case x.CanInt() && y.CanInt():
+		return x.Int() < y.Int()
+	case x.CanUint() && y.CanUint():
+		return x.Uint() < y.Uint()
+	}
+	fx, _ := toFloat(a)
+	fy, _ := toFloat(b)
+	return fx < fy
+}
+
+// Converts a number of any type to float64
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
 // Define the values in which the query results will be stored. These have to be
-// pointers.
+// pointers. For insert, update and delete queries these are the values in which the
+// columns defined with Returning will be stored.
 func (qb *Builder) Into(values ...interface{}) *Builder {
 	if qb.queryType == selectQry {
 		qb.values = append(qb.values, values...)
@@ -172,107 +1085,1099 @@ func (qb *Builder) Into(values ...interface{}) *Builder {
 func (qb *Builder) Join(joinType, table, column, fkey string) *Builder {
 	qb.joinTables = append(
 		qb.joinTables,
-		struct {
-			joinType, table, column, fkey string
-		}{
-			joinType, table, column, fkey,
+		join{
+			joinType: joinType,
+			table:    table,
+			column:   column,
+			fkey:     fkey,
 		},
 	)
 	return qb
 }
 
-// Define the where clause of the query.
-func (qb *Builder) Where(column, operator string, values ...interface{}) *Builder {
-	qb.criteria = append(
-		qb.criteria,
-		struct {
-			column   string
-			operator string
-			values   []interface{}
-			or       bool
-		}{
-			column:   column,
-			operator: strings.ToUpper(operator),
-			values:   values,
-			or:       false,
+// Define a join with an arbitrary ON condition, which can have ? placeholders for values,
+// like a range join
+//   - JoinCond("INNER", "rates", "rates.start<=orders.ts AND rates.end>orders.ts") or
+//   - JoinCond("LEFT", "items", "items.order_id=orders.id AND items.qty>?", 1)
+//
+// The join values are bound before the criteria values in Criteria() and Args(), since the
+// joins come before the WHERE clause. Will record an error if the number of ? is not equal
+// to the number of values.
+func (qb *Builder) JoinCond(joinType, table, condition string, values ...interface{}) *Builder {
+	qb.checkPlaceholders(condition, values)
+	qb.joinTables = append(
+		qb.joinTables,
+		join{
+			joinType:  joinType,
+			table:     table,
+			condition: condition,
+			values:    values,
 		},
 	)
 	return qb
 }
 
-// Define a where OR clause of the query.
-func (qb *Builder) OrWhere(column, operator string, values ...interface{}) *Builder {
-	qb.criteria = append(
-		qb.criteria,
-		struct {
-			column   string
-			operator string
-			values   []interface{}
-			or       bool
-		}{
-			column:   column,
-			operator: strings.ToUpper(operator),
-			values:   values,
-			or:       true,
-		},
-	)
+// Define a join on a table with an alias, which is needed to join the same table more than
+// once. For example
+//   - JoinAs("LEFT", "users", "m", "m.id", "u.manager_id") will produce
+//     LEFT JOIN users m ON m.id=u.manager_id
+func (qb *Builder) JoinAs(joinType, table, alias, column, fkey string) *Builder {
+	qb.Join(joinType, table, column, fkey)
+	qb.joinTables[len(qb.joinTables)-1].alias = alias
 	return qb
 }
 
-// Define an ascending order on a column
-func (qb *Builder) OrderBy(column string) *Builder {
-	qb.orderBy = append(
-		qb.orderBy,
-		struct {
-			column    string
-			direction sortOrder
-		}{
+// Makes the joins of a select generate in the legacy Oracle syntax, which lists the joined
+// tables in the FROM clause and adds the join conditions to the WHERE clause, marking the
+// columns of the optional side of an outer join with (+), like
+//   - FROM table1,table2 WHERE table2.table1_id(+)=table1.id
+//
+// for LeftJoin("table2", "table2.table1_id", "table1.id"). The joined table is the optional
+// side of a LEFT join and the other table of a RIGHT join. The legacy syntax is supported
+// only by Oracle, so generating the query for any other database engine will return an
+// error, as will FULL joins and joins with USING, which have no legacy form.
+func (qb *Builder) LegacyOracleJoins() *Builder {
+	qb.legacyJoins = true
+	return qb
+}
+
+// Paginates the query with the ROWNUM pseudocolumn of Oracle versions before 12c, which
+// have no OFFSET ... FETCH. The query is wrapped as
+//   - SELECT * FROM (SELECT a.*,ROWNUM rnum FROM (query) a WHERE ROWNUM<=:hi) WHERE rnum>:lo
+//
+// where hi is the offset plus the limit and lo is the offset, which are bound after all the
+// other values of the query. The rows have an additional rnum column, so a value must be
+// passed to Into for it. Queries without a limit are not wrapped. The ROWNUM pagination is
+// only supported by Oracle, so generating the query for any other database engine will
+// return an error.
+func (qb *Builder) OracleLegacyPagination() *Builder {
+	qb.legacyPagination = true
+	return qb
+}
+
+// Define a join on identically named columns of the joined tables with USING. For example
+//   - JoinUsing("LEFT", "table2", "id", "type") will produce LEFT JOIN table2 USING (id,type)
+func (qb *Builder) JoinUsing(joinType, table string, columns ...string) *Builder {
+	qb.joinTables = append(
+		qb.joinTables,
+		join{
+			joinType: joinType,
+			table:    table,
+			using:    columns,
+		},
+	)
+	return qb
+}
+
+// Define an INNER JOIN on column=fkey
+func (qb *Builder) InnerJoin(table, column, fkey string) *Builder {
+	return qb.Join("INNER", table, column, fkey)
+}
+
+// Define a LEFT JOIN on column=fkey
+func (qb *Builder) LeftJoin(table, column, fkey string) *Builder {
+	return qb.Join("LEFT", table, column, fkey)
+}
+
+// Define a RIGHT JOIN on column=fkey
+func (qb *Builder) RightJoin(table, column, fkey string) *Builder {
+	return qb.Join("RIGHT", table, column, fkey)
+}
+
+// Define a CROSS JOIN. Cross joins have no ON clause.
+func (qb *Builder) CrossJoin(table string) *Builder {
+	return qb.Join("CROSS", table, "", "")
+}
+
+// Define the where clause of the query. The column is generated as it is, so it can also be
+// a function expression, like WHERE LOWER(table1.name)=? for
+//   - Where("LOWER(table1.name)", "=", "value1")
+func (qb *Builder) Where(column, operator string, values ...interface{}) *Builder {
+	return qb.addCriterion(column, operator, values, false)
+}
+
+// Define a where OR clause of the query.
+func (qb *Builder) OrWhere(column, operator string, values ...interface{}) *Builder {
+	return qb.addCriterion(column, operator, values, true)
+}
+
+// Define an equality criterion for every column of a map, joined to the previous criteria
+// with AND, like
+//   - WhereMap(map[string]interface{}{"table1.b": 2, "table1.a": 1}) will produce
+//     WHERE table1.a=? AND table1.b=?
+//
+// The columns are sorted, so the same map always produces the same query, and the values
+// are bound in the same order.
+func (qb *Builder) WhereMap(criteria map[string]interface{}) *Builder {
+	columns := make([]string, 0, len(criteria))
+	for column := range criteria {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	for _, column := range columns {
+		qb.Where(column, "=", criteria[column])
+	}
+	return qb
+}
+
+// Define the where clause of the query only if cond is true. Otherwise the builder is
+// left unchanged, which keeps optional filters in a single chain. For example
+//   - WhereIf(name != "", "table1.name", "=", name)
+func (qb *Builder) WhereIf(cond bool, column, operator string, values ...interface{}) *Builder {
+	if !cond {
+		return qb
+	}
+	return qb.Where(column, operator, values...)
+}
+
+// Define a where OR clause of the query only if cond is true. Otherwise the builder is
+// left unchanged.
+func (qb *Builder) OrWhereIf(cond bool, column, operator string, values ...interface{}) *Builder {
+	if !cond {
+		return qb
+	}
+	return qb.OrWhere(column, operator, values...)
+}
+
+// Adds a criterion to the where clause. Invalid operators and values that do not match the
+// placeholders of the operator are recorded as errors of the builder immediately
+func (qb *Builder) addCriterion(column, operator string, values []interface{}, or bool) *Builder {
+	qb.criteria = append(qb.criteria, qb.newCriterion(column, operator, values, or))
+	return qb
+}
+
+// Creates a comparison criterion, recording an invalid operator or values that do not
+// match the placeholders of the operator as errors of the builder
+func (qb *Builder) newCriterion(column, operator string, values []interface{}, or bool) criterion {
+	operator = normalizeOperator(operator)
+	if !qb.operatorIsValid(operator) {
+		qb.addError(NewInvalidOperatorError(operator))
+	} else if err := checkOperatorValues(operator, len(values)); err != nil {
+		qb.addError(err)
+	}
+	return criterion{
+		column:   column,
+		operator: operator,
+		values:   values,
+		or:       or,
+	}
+}
+
+// Define an IN criterion on a column, joined to the previous criteria with AND. It is
+// validated like Where with the IN operator.
+func (qb *Builder) WhereIn(column string, values []interface{}) *Builder {
+	return qb.Where(column, "IN", values...)
+}
+
+// Define a NOT IN criterion on a column, joined to the previous criteria with AND. It is
+// validated like Where with the NOT IN operator.
+func (qb *Builder) WhereNotIn(column string, values []interface{}) *Builder {
+	return qb.Where(column, "NOT IN", values...)
+}
+
+// Define a BETWEEN low AND high criterion on a column, joined to the previous criteria
+// with AND
+func (qb *Builder) WhereBetween(column string, low, high interface{}) *Builder {
+	return qb.Where(column, "BETWEEN", low, high)
+}
+
+// Define a pattern match on a column with LIKE, where \ escapes the wildcards of the
+// pattern. Combined with EscapeLike this matches user input literally, like
+//   - WhereLike("table1.name", "%"+EscapeLike(input)+"%")
+//
+// MySQL and PostgreSQL use \ as the escape character by default, for the other database
+// engines an ESCAPE '\' clause is generated. Use Where with the LIKE operator when the
+// wildcards of the pattern are intended.
+func (qb *Builder) WhereLike(column, pattern string) *Builder {
+	qb.Where(column, "LIKE", pattern)
+	qb.criteria[len(qb.criteria)-1].escape = true
+	return qb
+}
+
+// Define a negated pattern match on a column with NOT LIKE, where \ escapes the wildcards
+// of the pattern. The same rules as in WhereLike apply.
+func (qb *Builder) WhereNotLike(column, pattern string) *Builder {
+	qb.Where(column, "NOT LIKE", pattern)
+	qb.criteria[len(qb.criteria)-1].escape = true
+	return qb
+}
+
+// Escapes the LIKE wildcards % and _, and the escape character \ itself, so that s is
+// matched literally by a pattern of WhereLike.
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Define a comparison of a column against any element of an array, like
+//   - WhereAny("table1.id", "=", pq.Array(ids)) will produce WHERE table1.id=ANY($1)
+//
+// The whole array is bound to a single placeholder, which is much cheaper than an IN with
+// a placeholder for every element. Only the =, <>, <, >, <= and >= operators can be used.
+// ANY is only supported by PostgreSQL, so generating the query for any other database
+// engine will return an error.
+func (qb *Builder) WhereAny(column, operator string, value interface{}) *Builder {
+	return qb.addQuantifiedCriterion(column, operator, "ANY", value)
+}
+
+// Define a comparison of a column against all the elements of an array, like
+//   - WhereAll("table1.price", ">", pq.Array(prices)) will produce WHERE table1.price>ALL($1)
+//
+// The same rules as in WhereAny apply.
+func (qb *Builder) WhereAll(column, operator string, value interface{}) *Builder {
+	return qb.addQuantifiedCriterion(column, operator, "ALL", value)
+}
+
+func (qb *Builder) addQuantifiedCriterion(column, operator, quantifier string, value interface{}) *Builder {
+	operator = normalizeOperator(operator)
+	if spec, ok := operators[operator]; !ok || spec.spaced {
+		qb.addError(NewInvalidOperatorError(operator))
+	}
+	qb.criteria = append(
+		qb.criteria,
+		criterion{
+			column:     column,
+			operator:   operator,
+			values:     []interface{}{value},
+			quantifier: quantifier,
+		},
+	)
+	return qb
+}
+
+// Define a comparison on the text of a key of a JSON column, joined to the previous criteria
+// with AND. For example
+//   - WhereJSONField("table1.data", "name", "=", "John") will produce
+//     WHERE table1.data->>'name'=$1
+//
+// The key is generated as a quoted literal and the value is bound to a placeholder. Only
+// operators with a single placeholder, like = or LIKE, can be used. JSON operators are only
+// supported by PostgreSQL, so generating the query for any other database engine will
+// return an error.
+func (qb *Builder) WhereJSONField(column, key, operator string, value interface{}) *Builder {
+	c := qb.newCriterion(column, operator, []interface{}{value}, false)
+	c.json = true
+	c.jsonKey = key
+	qb.criteria = append(qb.criteria, c)
+	return qb
+}
+
+// Define a containment criterion on a JSON column with @>, joined to the previous criteria
+// with AND. For example
+//   - WhereJSONContains("table1.data", `{"tags":["a"]}`) will produce WHERE table1.data@>$1
+//
+// The same rules as in WhereJSONField apply.
+func (qb *Builder) WhereJSONContains(column string, value interface{}) *Builder {
+	qb.criteria = append(
+		qb.criteria,
+		criterion{
+			column:   column,
+			operator: "@>",
+			values:   []interface{}{value},
+			json:     true,
+		},
+	)
+	return qb
+}
+
+// Define a case insensitive pattern match on a column with ILIKE. ILIKE is only supported
+// by PostgreSQL, so generating the query for any other database engine will return an error.
+func (qb *Builder) WhereILike(column, pattern string) *Builder {
+	return qb.Where(column, "ILIKE", pattern)
+}
+
+// Define a literal SQL fragment as a criterion of the where clause, joined to the previous
+// criteria with AND. Each ? in the fragment is replaced by a placeholder of the database
+// engine and the values are bound to them in order. The fragment is wrapped in parentheses,
+// so an OR in it cannot escape the other criteria. For example
+//   - WhereRaw("lower(table1.name)=lower(?)", "John") will produce WHERE (lower(table1.name)=lower(?))
+//
+// The fragment is not escaped or validated, so it must never contain untrusted input.
+// Untrusted values must always be passed as values.
+func (qb *Builder) WhereRaw(fragment string, values ...interface{}) *Builder {
+	qb.checkPlaceholders(fragment, values)
+	qb.criteria = append(
+		qb.criteria,
+		criterion{
+			column: fragment,
+			values: values,
+			or:     false,
+			raw:    true,
+		},
+	)
+	return qb
+}
+
+// Define a criterion comparing a column to a boolean literal, like table1.active=TRUE, joined
+// to the previous criteria with AND. The literal is generated in the query instead of being
+// bound to a placeholder, so a constant flag does not change the parameters of the query.
+// PostgreSQL has TRUE and FALSE, while 1 and 0 are generated for the other database engines.
+func (qb *Builder) WhereBool(column string, value bool) *Builder {
+	qb.criteria = append(
+		qb.criteria,
+		criterion{
+			column:   column,
+			operator: "=",
+			values:   []interface{}{value},
+			literal:  true,
+		},
+	)
+	return qb
+}
+
+// Define a literal SQL fragment as a criterion of the where clause, joined to the previous
+// criteria with OR. The same rules as in WhereRaw apply.
+func (qb *Builder) OrWhereRaw(fragment string, values ...interface{}) *Builder {
+	qb.checkPlaceholders(fragment, values)
+	qb.criteria = append(
+		qb.criteria,
+		criterion{
+			column: fragment,
+			values: values,
+			or:     true,
+			raw:    true,
+		},
+	)
+	return qb
+}
+
+// Define an EXISTS criterion on a subquery, joined to the previous criteria with AND.
+// The subquery must be a SELECT builder and is generated for the database engine of
+// this builder, continuing its placeholder numbering. Its criteria values are included
+// in Criteria() in the position of the subquery.
+func (qb *Builder) WhereExists(sub *Builder) *Builder {
+	qb.criteria = append(
+		qb.criteria,
+		criterion{
+			operator: "EXISTS",
+			sub:      sub,
+		},
+	)
+	return qb
+}
+
+// Define a NOT EXISTS criterion on a subquery, joined to the previous criteria with AND.
+// The same rules as in WhereExists apply.
+func (qb *Builder) WhereNotExists(sub *Builder) *Builder {
+	qb.criteria = append(
+		qb.criteria,
+		criterion{
+			operator: "NOT EXISTS",
+			sub:      sub,
+		},
+	)
+	return qb
+}
+
+// Define an IN criterion on the result of a subquery, joined to the previous criteria
+// with AND. For example
+//   - WhereInSubquery("table1.id", NewSelect("table2").Select("table1_id")) will produce
+//     WHERE table1.id IN (SELECT table2.table1_id FROM table2)
+//
+// The same rules as in WhereExists apply to the subquery.
+func (qb *Builder) WhereInSubquery(column string, sub *Builder) *Builder {
+	qb.criteria = append(
+		qb.criteria,
+		criterion{
+			column:   column,
+			operator: "IN",
+			sub:      sub,
+		},
+	)
+	return qb
+}
+
+// Define an IN criterion on multiple columns with row values, joined to the previous
+// criteria with AND. For example
+//   - WhereRowIn([]string{"table1.a", "table1.b"}, [][]interface{}{{1, 2}, {3, 4}}) will
+//     produce WHERE (table1.a,table1.b) IN ((?,?),(?,?))
+//
+// The values are bound row by row. Every row must have a value for each column, otherwise
+// ErrBadColumnsValuesCombo is recorded. Row values are not supported by SQL Server, so
+// generating the query for it will return an error. No rows are handled like an empty IN.
+func (qb *Builder) WhereRowIn(columns []string, rows [][]interface{}) *Builder {
+	var values []interface{}
+	for _, row := range rows {
+		if len(row) != len(columns) {
+			qb.addError(NewBadColumnsValuesComboError(len(columns), len(row)))
+		}
+		values = append(values, row...)
+	}
+	qb.criteria = append(
+		qb.criteria,
+		criterion{
+			operator:   "IN",
+			values:     values,
+			rowColumns: columns,
+		},
+	)
+	return qb
+}
+
+// Define a comparison of a column against another column, joined to the previous criteria
+// with AND. No value is bound, so for example
+//   - WhereColumn("a.created_at", "<", "b.updated_at") will produce WHERE a.created_at<b.updated_at
+//
+// Columns without a table are prefixed like in Select when the query is generated. Only
+// the =, <>, <, >, <= and >= operators can be used.
+func (qb *Builder) WhereColumn(column1, operator, column2 string) *Builder {
+	return qb.addColumnCriterion(column1, operator, column2, false)
+}
+
+// Define a comparison of a column against another column, joined to the previous criteria
+// with OR. The same rules as in WhereColumn apply.
+func (qb *Builder) OrWhereColumn(column1, operator, column2 string) *Builder {
+	return qb.addColumnCriterion(column1, operator, column2, true)
+}
+
+func (qb *Builder) addColumnCriterion(column1, operator, column2 string, or bool) *Builder {
+	operator = normalizeOperator(operator)
+	if spec, ok := operators[operator]; !ok || spec.spaced {
+		qb.addError(NewInvalidOperatorError(operator))
+	}
+	qb.criteria = append(
+		qb.criteria,
+		criterion{
+			column:   column1,
+			operator: operator,
+			or:       or,
+			column2:  column2,
+		},
+	)
+	return qb
+}
+
+// Define a comparison of a column against the result of a scalar subquery, joined to the
+// previous criteria with AND. For example
+//   - WhereSubquery("table1.price", ">", NewSelect("table2").Select("AVG(table2.price)"))
+//     will produce WHERE table1.price > (SELECT AVG(table2.price) FROM table2)
+//
+// Only the =, <>, <, >, <= and >= operators can be used, since IN, BETWEEN and LIKE have
+// their own shapes, like WhereInSubquery. The same rules as in WhereExists apply to the
+// subquery.
+func (qb *Builder) WhereSubquery(column, operator string, sub *Builder) *Builder {
+	operator = normalizeOperator(operator)
+	if spec, ok := operators[operator]; !ok || spec.spaced {
+		qb.addError(NewInvalidOperatorError(operator))
+	}
+	qb.criteria = append(
+		qb.criteria,
+		criterion{
+			column:   column,
+			operator: operator,
+			sub:      sub,
+		},
+	)
+	return qb
+}
+
+// Define a group of criteria that will be wrapped in parentheses and joined to the
+// previous criteria with AND. The criteria of the group are defined in the callback
+// by calling Where, OrWhere etc. on the builder it receives. For example
+//   - Where("a", "=", 1).WhereGroup(func(g *Builder) { g.Where("b", "=", 2).OrWhere("c", "=", 3) })
+//     will produce WHERE a=? AND (b=? OR c=?)
+func (qb *Builder) WhereGroup(group func(*Builder)) *Builder {
+	return qb.addGroup(group, false)
+}
+
+// Define a group of criteria that will be wrapped in parentheses and joined to the
+// previous criteria with OR.
+func (qb *Builder) OrWhereGroup(group func(*Builder)) *Builder {
+	return qb.addGroup(group, true)
+}
+
+func (qb *Builder) addGroup(group func(*Builder), or bool) *Builder {
+	gb := &Builder{
+		db:               qb.db,
+		table:            qb.table,
+		alias:            qb.alias,
+		allowedOperators: qb.allowedOperators,
+		noAutoPrefix:     qb.noAutoPrefix,
+	}
+	group(gb)
+	if gb.err != nil {
+		qb.addError(gb.err)
+	}
+	qb.criteria = append(
+		qb.criteria,
+		criterion{
+			or:    or,
+			group: append([]criterion{}, gb.criteria...),
+		},
+	)
+	return qb
+}
+
+// Appends the criteria of the WHERE clause of another builder to the criteria of the query,
+// so shared filters can be defined once and composed across queries. The criteria are
+// wrapped in parentheses and joined to the previous criteria with AND, as in WhereGroup, so
+// an OR among them cannot escape the existing criteria. The first of them is joined with
+// AND inside the parentheses. Their values follow the values of
+// the existing criteria in Criteria. The columns compared with WhereColumn are prefixed
+// with the table of the other builder, so they keep referring to it. An error recorded on
+// the other builder is recorded on the query as well, and the operators allowed on it are
+// allowed on the query.
+func (qb *Builder) MergeWhere(other *Builder) *Builder {
+	if other.err != nil {
+		qb.addError(other.err)
+	}
+	qb.AllowOperators(other.allowedOperators...)
+	if len(other.criteria) == 0 {
+		return qb
+	}
+	group := other.prefixColumnCriteria(other.criteria)
+	group[0].or = false
+	qb.criteria = append(
+		qb.criteria,
+		criterion{
+			group: group,
+		},
+	)
+	return qb
+}
+
+// Returns a copy of criteria with the columns compared with WhereColumn, including the ones
+// of groups, prefixed with the table of the builder
+func (qb *Builder) prefixColumnCriteria(criteria []criterion) []criterion {
+	prefixed := append([]criterion{}, criteria...)
+	for i, c := range prefixed {
+		switch {
+		case c.group != nil:
+			prefixed[i].group = qb.prefixColumnCriteria(c.group)
+		case c.column2 != "":
+			prefixed[i].column = qb.prefixColumn(c.column)
+			prefixed[i].column2 = qb.prefixColumn(c.column2)
+		}
+	}
+	return prefixed
+}
+
+// Define the columns to group the rows of a SELECT query by, with GROUP BY. Columns without
+// a table are prefixed, as in Select.
+func (qb *Builder) GroupBy(columns ...string) *Builder {
+	for _, column := range columns {
+		qb.groupBy = append(qb.groupBy, qb.prefixColumn(column))
+	}
+	return qb
+}
+
+// Define a criterion of the HAVING clause of a grouped query, joined to the previous HAVING
+// criteria with AND. The column is usually an aggregate, like COUNT(*). The same rules as in
+// Where apply, and the values are bound after the values of the WHERE clause.
+func (qb *Builder) Having(column, operator string, values ...interface{}) *Builder {
+	qb.having = append(qb.having, qb.newCriterion(column, operator, values, false))
+	return qb
+}
+
+// Define a literal SQL fragment as a criterion of the HAVING clause of a grouped query,
+// joined to the previous HAVING criteria with AND. It is useful for aggregate expressions,
+// which are not columns. For example
+//   - HavingRaw("COUNT(*)>?", 10) will produce HAVING (COUNT(*)>?)
+//
+// The same rules as in WhereRaw apply, so the fragment is wrapped in parentheses and an OR
+// in it cannot escape the other HAVING criteria.
+func (qb *Builder) HavingRaw(fragment string, values ...interface{}) *Builder {
+	qb.checkPlaceholders(fragment, values)
+	qb.having = append(
+		qb.having,
+		criterion{
+			column: fragment,
+			values: values,
+			raw:    true,
+		},
+	)
+	return qb
+}
+
+// Define a criterion of the HAVING clause of a grouped query, joined to the previous HAVING
+// criteria with OR.
+func (qb *Builder) OrHaving(column, operator string, values ...interface{}) *Builder {
+	qb.having = append(qb.having, qb.newCriterion(column, operator, values, true))
+	return qb
+}
+
+// Define multiple criteria at once, each joined to the previous criteria with AND. Each
+// condition is validated like Where. For example
+//   - WhereAllOf(Condition{"a", "=", []interface{}{1}}, Condition{"b", ">", []interface{}{2}})
+//     will produce WHERE a=? AND b>?
+func (qb *Builder) WhereAllOf(conditions ...Condition) *Builder {
+	for _, c := range conditions {
+		qb.Where(c.Column, c.Operator, c.Values...)
+	}
+	return qb
+}
+
+// Define a group of criteria joined with OR, which is wrapped in parentheses and joined to
+// the previous criteria with AND, as in WhereGroup. For example
+//   - WhereAnyOf(Condition{"a", "=", []interface{}{1}}, Condition{"b", ">", []interface{}{2}})
+//     will produce WHERE (a=? OR b>?)
+//
+// Generating the query will return ErrEmptyWhereGroup if there are no conditions.
+func (qb *Builder) WhereAnyOf(conditions ...Condition) *Builder {
+	return qb.WhereGroup(func(g *Builder) {
+		for i, c := range conditions {
+			g.addCriterion(c.Column, c.Operator, c.Values, i > 0)
+		}
+	})
+}
+
+// Combines the results of the query with the results of another SELECT query with UNION,
+// removing duplicate rows. Multiple queries can be combined by chaining this. The other
+// query is generated for the database engine of this builder, continuing its placeholder
+// numbering, and its criteria values follow the ones of this builder in Criteria(). The
+// ORDER BY and LIMIT clauses of this builder apply to the combined results. Will return an
+// error on generation if the queries select a different number of columns.
+func (qb *Builder) Union(other *Builder) *Builder {
+	qb.unions = append(qb.unions, union{all: false, sub: other})
+	return qb
+}
+
+// Combines the results of the query with the results of another SELECT query with
+// UNION ALL, keeping duplicate rows. The same rules as in Union apply.
+func (qb *Builder) UnionAll(other *Builder) *Builder {
+	qb.unions = append(qb.unions, union{all: true, sub: other})
+	return qb
+}
+
+// Define an ascending order on a column. Columns without a table are prefixed when the
+// query is generated, as in Select, except the aliases of selected expressions and the
+// columns of queries combined with UNION, which can only be ordered by the result columns.
+func (qb *Builder) OrderBy(column string) *Builder {
+	return qb.OrderByDirection(column, false)
+}
+
+// Define a descending order on a column
+func (qb *Builder) OrderByDescending(column string) *Builder {
+	return qb.OrderByDirection(column, true)
+}
+
+// Define an order on a column with the direction given as an argument, which is useful when
+// the direction is not known beforehand. The order is descending if desc is true and
+// ascending otherwise.
+func (qb *Builder) OrderByDirection(column string, desc bool) *Builder {
+	direction := ascending
+	if desc {
+		direction = descending
+	}
+	qb.orderBy = append(
+		qb.orderBy,
+		ordering{
+			column:    column,
+			direction: direction,
+		},
+	)
+	return qb
+}
+
+// Define an order on multiple columns at once. Columns are ordered ascending unless they
+// have a direction suffix, as in "field1 DESC" or "field2 ASC".
+func (qb *Builder) OrderByColumns(columns ...string) *Builder {
+	for _, column := range columns {
+		column = strings.TrimSpace(column)
+		desc := false
+		if i := strings.LastIndex(column, " "); i != -1 {
+			switch strings.ToUpper(column[i+1:]) {
+			case "DESC":
+				desc = true
+				column = strings.TrimSpace(column[:i])
+			case "ASC":
+				column = strings.TrimSpace(column[:i])
+			}
+		}
+		qb.OrderByDirection(column, desc)
+	}
+	return qb
+}
+
+// Define an order on multiple columns at once from a list of specs, like the sort fields of
+// an API request. Each spec is ordered like OrderByDirection, so its column is prefixed and
+// checked against the columns allowed with AllowColumns.
+func (qb *Builder) OrderByMany(specs []OrderSpec) *Builder {
+	for _, spec := range specs {
+		qb.OrderByDirection(spec.Column, spec.Descending)
+	}
+	return qb
+}
+
+// Define an ascending order on selected columns by their position in the SELECT clause,
+// starting from 1, like ORDER BY 1,2. This is useful for aggregates whose expression is
+// awkward to repeat. Will record ErrInvalidOrdinal if a position is less than 1.
+func (qb *Builder) OrderByOrdinal(positions ...int) *Builder {
+	for _, position := range positions {
+		if position < 1 {
+			qb.addError(ErrInvalidOrdinal)
+		}
+		qb.orderBy = append(
+			qb.orderBy,
+			ordering{
+				column:    strconv.Itoa(position),
+				direction: ascending,
+				ordinal:   true,
+			},
+		)
+	}
+	return qb
+}
+
+// Define a custom order on a column, placing the rows in the order of the given values, like
+// ORDER BY FIELD(id,3,1,2) in MySQL. The values are bound to placeholders. Other database
+// engines have no FIELD function, so an equivalent CASE expression is generated instead,
+// like CASE id WHEN 3 THEN 1 WHEN 1 THEN 2 WHEN 2 THEN 3 ELSE 0 END. As with FIELD, rows
+// with a value not in the list are placed first. Will record ErrEmptyOrderByField if no
+// values are given.
+func (qb *Builder) OrderByField(column string, values ...interface{}) *Builder {
+	if len(values) == 0 {
+		qb.addError(ErrEmptyOrderByField)
+	}
+	qb.orderBy = append(
+		qb.orderBy,
+		ordering{
 			column:    column,
 			direction: ascending,
+			values:    values,
 		},
 	)
 	return qb
 }
 
-// Define a descending order on a column
-func (qb *Builder) OrderByDescending(column string) *Builder {
-	qb.orderBy = append(
-		qb.orderBy,
-		struct {
-			column    string
-			direction sortOrder
-		}{
-			column:    column,
-			direction: descending,
-		},
-	)
-	return qb
+// Define an ascending order on a column with NULL values placed first. NULLS FIRST is
+// supported by PostgreSQL, Oracle and SQLite, so generating the query for any other
+// database engine will return an error.
+func (qb *Builder) OrderByNullsFirst(column string) *Builder {
+	qb.OrderBy(column)
+	qb.orderBy[len(qb.orderBy)-1].nulls = nullsFirst
+	return qb
+}
+
+// Define an ascending order on a column with NULL values placed last. The same dialect
+// rules as in OrderByNullsFirst apply.
+func (qb *Builder) OrderByNullsLast(column string) *Builder {
+	qb.OrderBy(column)
+	qb.orderBy[len(qb.orderBy)-1].nulls = nullsLast
+	return qb
+}
+
+// Define a descending order on a column with NULL values placed first. The same dialect
+// rules as in OrderByNullsFirst apply.
+func (qb *Builder) OrderByDescendingNullsFirst(column string) *Builder {
+	qb.OrderByDescending(column)
+	qb.orderBy[len(qb.orderBy)-1].nulls = nullsFirst
+	return qb
+}
+
+// Define a descending order on a column with NULL values placed last, like the latest
+// rows first with the ones without a date at the end. The same dialect rules as in
+// OrderByNullsFirst apply.
+func (qb *Builder) OrderByDescendingNullsLast(column string) *Builder {
+	qb.OrderByDescending(column)
+	qb.orderBy[len(qb.orderBy)-1].nulls = nullsLast
+	return qb
+}
+
+// Define an order on a literal SQL expression, like RANDOM() or "field1 DESC". The
+// expression is generated as is, so any direction must be part of it. The expression is
+// not escaped or validated, so it must never contain untrusted input.
+func (qb *Builder) OrderByRaw(expression string) *Builder {
+	qb.orderBy = append(
+		qb.orderBy,
+		ordering{
+			column: expression,
+			raw:    true,
+		},
+	)
+	return qb
+}
+
+// Returns the table of the query, as set in the constructor or with From
+func (qb *Builder) Table() string {
+	return qb.table
+}
+
+// Returns a copy of the columns of the query, which are the selected columns of a select
+// or the set columns of an insert / update. Selected columns without a table are returned
+// prefixed like in Select, while subqueries selected with SelectSubquery are returned as
+// (subquery) AS alias. Set columns are returned as they were passed to Set, SetRaw and the
+// other methods that define them, without a prefix. Changing the returned slice does not
+// affect the builder.
+func (qb *Builder) Columns() []string {
+	return append([]string(nil), qb.columns...)
+}
+
+// Returns the pointer values in which the results will be stored, or the values of an
+// insert / update in the order of their placeholders. Use Args to get all the values to
+// bind to the placeholders of a query.
+func (qb *Builder) Values() []interface{} {
+	var values []interface{}
+	for _, value := range qb.values {
+		switch v := value.(type) {
+		case rawExpression:
+			values = append(values, v.values...)
+		case sqlKeyword:
+		default:
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Define the auto-increment column of an insert, whose generated value will be stored in
+// the single value passed to Into when the query is executed with ExecInsert. For MySQL,
+// which does not support RETURNING, the column is not generated in the query and the value
+// is read from the LastInsertId of the result instead, so generating a MySQL query with
+// other returning columns returns ErrDBEngineDoesNotSupportReturning. For the other
+// database engines the column is returned with RETURNING.
+func (qb *Builder) LastInsertIDColumn(name string) *Builder {
+	qb.lastInsertID = true
+	return qb.Returning(name)
+}
+
+// Returns the pointer values in which the returning values for a PostgreSQL, Oracle or SQLite
+// Insert, Update, Delete query with returning will be stored
+func (qb *Builder) ReturningValues() []interface{} {
+	return qb.returnValues
+}
+
+// Returns the criteria values that have been defined with Where, preceded by the criteria
+// values of the common table expressions defined with With and the values of the joins
+// defined with JoinCond
+func (qb *Builder) Criteria() []interface{} {
+	return append(qb.cteValues(), qb.clauseValues()...)
+}
+
+// Returns the criteria values of the common table expressions
+func (qb *Builder) cteValues() []interface{} {
+	var values []interface{}
+	for _, cte := range qb.ctes {
+		values = append(values, cte.sub.Criteria()...)
+	}
+	return values
+}
+
+// Returns the criteria values of the clauses that follow the SET / VALUES of an update or
+// insert, in the order of their placeholders
+func (qb *Builder) clauseValues() []interface{} {
+	values := qb.selectValues()
+	if qb.fromSub != nil {
+		values = append(values, qb.fromSub.Criteria()...)
+	}
+	values = append(values, qb.joinValues()...)
+	values = append(values, qb.filterValues()...)
+	return append(values, qb.paginationValues()...)
+}
+
+// Returns the criteria values of the subqueries selected with SelectSubquery, in the order
+// of the selected columns
+func (qb *Builder) selectValues() []interface{} {
+	var values []interface{}
+	for _, column := range qb.columns {
+		if sub, ok := qb.selectSubqueries[column]; ok {
+			values = append(values, sub.Criteria()...)
+		}
+		values = append(values, qb.selectFunctions[column].values...)
+	}
+	return values
+}
+
+// Returns the values of the join conditions
+func (qb *Builder) joinValues() []interface{} {
+	var values []interface{}
+	for _, joinTable := range qb.joinTables {
+		values = append(values, joinTable.values...)
+	}
+	return values
 }
 
-// Returns the pointer values in which the results will be stored
-func (qb *Builder) Values() []interface{} {
-	return qb.values
+// Returns the criteria values of the WHERE and HAVING clauses and the combined queries,
+// followed by the values of the orders defined with OrderByField
+func (qb *Builder) filterValues() []interface{} {
+	values := criteriaValues(qb.criteria)
+	values = append(values, criteriaValues(qb.having)...)
+	for _, union := range qb.unions {
+		values = append(values, union.sub.Criteria()...)
+	}
+	for _, order := range qb.orderBy {
+		values = append(values, order.values...)
+	}
+	return values
 }
 
-// Returns the pointer values in which the returning values for a PostgreSQL or Oracle
-// Insert, Update, Delete query with returning will be stored
-func (qb *Builder) ReturningValues() []interface{} {
-	return qb.returnValues
+// Returns all the values to bind to the placeholders of the query, in the order the
+// placeholders appear in the query, so they can be passed as the arguments of the query
+// to database/sql. For insert and update queries these are the values defined with To,
+// followed by the criteria values, which matches SET col=? ... WHERE col=?. For select and
+// delete queries these are the criteria values only, since the values of a select are the
+// destinations the results are scanned into and are not bound to any placeholder. The values
+// of the joins defined with JoinCond come before the criteria values, or before the values
+// defined with To for MySQL updates, which generate the joins before SET.
+// The values of the orders defined with OrderByField come last.
+func (qb *Builder) Args() []interface{} {
+	args := qb.cteValues()
+	if qb.queryType == updateQry && qb.db == MYSQL {
+		// MySQL joins are generated in the UPDATE clause, before SET
+		args = append(args, qb.joinValues()...)
+		args = append(args, qb.Values()...)
+		return append(args, qb.filterValues()...)
+	}
+	if qb.queryType == insertQry || qb.queryType == updateQry {
+		args = append(args, qb.Values()...)
+	}
+	return append(args, qb.clauseValues()...)
 }
 
-// Returns the criteria values that have been defined with Where
-func (qb *Builder) Criteria() []interface{} {
+// Flattens the values of a list of criteria, including the ones of nested groups,
+// in the order their placeholders appear in the query
+func criteriaValues(criteria []criterion) []interface{} {
 	var values []interface{}
-	for _, criterion := range qb.criteria {
+	for _, criterion := range criteria {
+		if criterion.group != nil {
+			values = append(values, criteriaValues(criterion.group)...)
+			continue
+		}
+		if criterion.sub != nil {
+			values = append(values, criterion.sub.Criteria()...)
+			continue
+		}
+		if criterion.literal {
+			continue
+		}
 		values = append(values, criterion.values...)
 	}
 	return values
 }
 
-// Generates the query string.
+// Creates a new SELECT builder that counts the rows of this query. The new builder has the
+// same database engine, common table expressions, FROM, index hints, JOIN and WHERE clauses,
+// but selects COUNT(*) instead of the columns and has no ORDER BY and LIMIT. A query with
+// DISTINCT or DISTINCT ON is counted as a subquery instead, without its ORDER BY and LIMIT,
+// like SELECT COUNT(*) FROM (SELECT DISTINCT ...) sub, since its rows depend on the selected
+// columns. The value in which the count will be stored must be defined with Into on the new
+// builder. For example
+//   - cq, err := qb.CountQuery(); cq.Into(&total)
+//
+// Will return an error if this query is not a SELECT query or is combined with UNION.
+func (qb *Builder) CountQuery() (*Builder, error) {
+	if qb.queryType != selectQry {
+		return nil, ErrNotSelectQuery
+	}
+	if qb.err != nil {
+		return nil, qb.err
+	}
+	if len(qb.unions) > 0 {
+		return nil, ErrCountQueryWithUnion
+	}
+	if len(qb.groupBy) > 0 {
+		return nil, ErrCountQueryWithGroupBy
+	}
+	if qb.distinct || len(qb.distinctOn) > 0 {
+		return qb.distinctCountQuery(), nil
+	}
+	return &Builder{
+		db:               qb.db,
+		queryType:        selectQry,
+		ctes:             append([]cte{}, qb.ctes...),
+		table:            qb.table,
+		fromSub:          qb.fromSub,
+		alias:            qb.alias,
+		indexHints:       append([]indexHint{}, qb.indexHints...),
+		joinTables:       append([]join{}, qb.joinTables...),
+		columns:          []string{"COUNT(*)"},
+		criteria:         append([]criterion{}, qb.criteria...),
+		legacyJoins:      qb.legacyJoins,
+		allowedOperators: append([]string(nil), qb.allowedOperators...),
+		emptyInAsFalse:   qb.emptyInAsFalse,
+	}, nil
+}
+
+// Creates the count query of a DISTINCT query, which selects COUNT(*) from a copy of the
+// query without its common table expressions, values, ORDER BY, LIMIT and row lock. The
+// common table expressions are moved to the count query, so they can still be referenced.
+func (qb *Builder) distinctCountQuery() *Builder {
+	inner := qb.copyFields()
+	inner.ctes = nil
+	inner.values = nil
+	inner.orderBy = nil
+	inner.limit, inner.offset, inner.hasLimit = 0, 0, false
+	inner.withTies, inner.legacyPagination = false, false
+	inner.lock, inner.lockWait = noLock, waitLocked
+	cq := &Builder{
+		db:        qb.db,
+		queryType: selectQry,
+		ctes:      append([]cte{}, qb.ctes...),
+		columns:   []string{"COUNT(*)"},
+	}
+	return cq.FromSubquery(&inner, "sub")
+}
+
+// Returns a copy of the builder that shares none of its slices and maps with it, so
+// reusing the builder after Reset, which keeps the arrays of some of its slices, does not
+// change the copy. Subqueries are not copied.
+func (qb *Builder) copyFields() Builder {
+	copied := *qb
+	copied.ctes = append([]cte(nil), qb.ctes...)
+	copied.indexHints = append([]indexHint(nil), qb.indexHints...)
+	copied.joinTables = append([]join(nil), qb.joinTables...)
+	copied.distinctOn = append([]string(nil), qb.distinctOn...)
+	copied.columns = append([]string(nil), qb.columns...)
+	copied.returningColumns = append([]string(nil), qb.returningColumns...)
+	copied.deleteTables = append([]string(nil), qb.deleteTables...)
+	copied.values = append([]interface{}(nil), qb.values...)
+	copied.returnValues = append([]interface{}(nil), qb.returnValues...)
+	copied.criteria = append([]criterion(nil), qb.criteria...)
+	copied.groupBy = append([]string(nil), qb.groupBy...)
+	copied.having = append([]criterion(nil), qb.having...)
+	copied.unions = append([]union(nil), qb.unions...)
+	copied.orderBy = append([]ordering(nil), qb.orderBy...)
+	copied.allowedColumns = append([]string(nil), qb.allowedColumns...)
+	copied.allowedOperators = append([]string(nil), qb.allowedOperators...)
+	if qb.selectSubqueries != nil {
+		copied.selectSubqueries = make(map[string]*Builder, len(qb.selectSubqueries))
+		for column, sub := range qb.selectSubqueries {
+			copied.selectSubqueries[column] = sub
+		}
+	}
+	if qb.selectFunctions != nil {
+		copied.selectFunctions = make(map[string]selectedFunction, len(qb.selectFunctions))
+		for column, function := range qb.selectFunctions {
+			copied.selectFunctions[column] = function
+		}
+	}
+	if qb.expressions != nil {
+		copied.expressions = make(map[string][]string, len(qb.expressions))
+		for expression, sources := range qb.expressions {
+			copied.expressions[expression] = sources
+		}
+	}
+	return copied
+}
+
+// Generates the query string. Will return the first error recorded while defining the
+// query, if any. The placeholders are numbered from the start on every call, so generating
+// the same query twice produces the same string.
 func (qb *Builder) GenerateQuery() (string, error) {
+	qb.placeholderCount = 0
+	return qb.generateQuery()
+}
+
+// Generates the query string, numbering the placeholders from the current placeholder count
+func (qb *Builder) generateQuery() (string, error) {
+	if qb.err != nil {
+		return "", qb.err
+	}
+	if qb.table == "" {
+		return "", ErrEmptyTableName
+	}
+	if err := qb.checkIdentifiers(); err != nil {
+		return "", err
+	}
+	if err := qb.checkAllowedColumns(); err != nil {
+		return "", err
+	}
+	withClause, err := qb.generateWithClause()
+	if err != nil {
+		return "", err
+	}
 	var qry string
-	var err error
 	switch qb.queryType {
 	case selectQry:
 		qry, err = qb.generateSelectQry()
@@ -282,8 +2187,202 @@ func (qb *Builder) GenerateQuery() (string, error) {
 		qry, err = qb.generateUpdateQry()
 	case deleteQry:
 		qry, err = qb.generateDeleteQry()
+	case truncateQry:
+		qry, err = qb.generateTruncateQry()
 	}
-	return qry, err
+	if err != nil {
+		return "", err
+	}
+	return withClause + qry, nil
+}
+
+// Checks the builder and returns the first error that GenerateQuery would return, like an
+// invalid operator, a mismatch between columns and values, a clause that the database
+// engine does not support or an empty table, or nil if the query is valid. This is useful
+// to validate a query defined from a request before executing it. The checks depend on each
+// other, so they run through the query generation, which costs as much as GenerateQuery.
+// The query is generated on a copy of the builder and discarded, so neither the builder nor
+// its subqueries are changed.
+func (qb *Builder) Validate() error {
+	copied := *qb
+	_, err := copied.GenerateQuery()
+	return err
+}
+
+// Generates the query string together with the values to bind to its placeholders, as
+// returned by Args. The result can be passed directly to database/sql, like
+//   - qry, args, err := qb.Build(); rows, err := db.Query(qry, args...)
+func (qb *Builder) Build() (string, []interface{}, error) {
+	qry, err := qb.GenerateQuery()
+	if err != nil {
+		return "", nil, err
+	}
+	return qry, qb.Args(), nil
+}
+
+// Checks that every column used in the query has been allowed with AllowColumns. Will
+// return error on the first column that is not allowed.
+func (qb *Builder) checkAllowedColumns() error {
+	if qb.allowedColumns == nil {
+		return nil
+	}
+	allowed := make(map[string]bool, len(qb.allowedColumns))
+	for _, column := range qb.allowedColumns {
+		allowed[qb.prefixColumn(column)] = true
+	}
+	for _, column := range qb.usedColumns() {
+		if !allowed[qb.prefixColumn(column)] {
+			return NewDisallowedColumnError(column)
+		}
+	}
+	return nil
+}
+
+// Checks that the tables and the columns used in the query are valid identifiers. Will
+// return error on the first invalid identifier.
+func (qb *Builder) checkIdentifiers() error {
+	identifiers := append([]string{qb.table}, qb.deleteTables...)
+	if qb.alias != "" {
+		identifiers = append(identifiers, qb.alias)
+	}
+	for _, h := range qb.indexHints {
+		identifiers = append(identifiers, h.index)
+	}
+	for _, j := range qb.joinTables {
+		identifiers = append(identifiers, j.table)
+		if j.alias != "" {
+			identifiers = append(identifiers, j.alias)
+		}
+	}
+	for _, column := range qb.columns {
+		if _, ok := qb.expressions[column]; ok {
+			continue
+		}
+		if _, alias := splitAlias(column); alias != "" {
+			identifiers = append(identifiers, alias)
+		}
+	}
+	for _, identifier := range append(identifiers, qb.usedColumns()...) {
+		if !isValidIdentifier(identifier) {
+			return NewInvalidIdentifierError(identifier)
+		}
+	}
+	return nil
+}
+
+// Checks that an identifier only has letters, digits and the characters _ . * ( ), which
+// allow qualified names, wildcards and function expressions. Parts of the identifier can be
+// quoted with double quotes, backticks or brackets, like table1."order", and can then have
+// any character but their closing quote.
+func isValidIdentifier(identifier string) bool {
+	if identifier == "" {
+		return false
+	}
+	for i := 0; i < len(identifier); i++ {
+		c := identifier[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("_.*()", c) >= 0:
+		case strings.IndexByte("\"`[", c) >= 0:
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(identifier[i+1:], closing)
+			if end <= 0 {
+				return false
+			}
+			i += end + 1
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Splits a selected column into the column and its alias, like "table1.column1 AS c1",
+// matching AS case insensitively. The alias is empty if the column has none.
+func splitAlias(column string) (string, string) {
+	for i := len(column) - len(" AS "); i > 0; i-- {
+		if strings.EqualFold(column[i:i+len(" AS ")], " AS ") {
+			return column[:i], column[i+len(" AS "):]
+		}
+	}
+	return column, ""
+}
+
+// Returns the columns used in the selected, returning, set, join, WHERE, GROUP BY, HAVING
+// and ORDER BY columns of the query. The columns of selected expressions are returned
+// instead of the expressions, and selected columns without their alias, while raw criteria
+// and orders, and orders on the aliases of selected columns, are skipped.
+func (qb *Builder) usedColumns() []string {
+	var columns []string
+	for _, column := range qb.columns {
+		if sources, ok := qb.expressions[column]; ok {
+			columns = append(columns, sources...)
+		} else {
+			column, _ = splitAlias(column)
+			columns = append(columns, column)
+		}
+	}
+	columns = append(columns, qb.distinctOn...)
+	for _, column := range qb.returningColumns {
+		if column != "*" {
+			columns = append(columns, column)
+		}
+	}
+	for _, j := range qb.joinTables {
+		if j.column != "" {
+			columns = append(columns, j.column, j.fkey)
+		}
+		columns = append(columns, j.using...)
+	}
+	columns = appendCriteriaColumns(columns, qb.criteria)
+	columns = append(columns, qb.groupBy...)
+	columns = appendCriteriaColumns(columns, qb.having)
+	for _, order := range qb.orderBy {
+		if !order.raw && !order.ordinal && !qb.isSelectedAlias(order.column) {
+			columns = append(columns, order.column)
+		}
+	}
+	return columns
+}
+
+// Appends the columns of non raw criteria, including the ones of groups, to columns
+func appendCriteriaColumns(columns []string, criteria []criterion) []string {
+	for _, c := range criteria {
+		switch {
+		case c.group != nil:
+			columns = appendCriteriaColumns(columns, c.group)
+		case len(c.rowColumns) > 0:
+			columns = append(columns, c.rowColumns...)
+		case !c.raw && c.column != "":
+			columns = append(columns, c.column)
+			if c.column2 != "" {
+				columns = append(columns, c.column2)
+			}
+		}
+	}
+	return columns
+}
+
+// Generates the WITH clause of the common table expressions
+func (qb *Builder) generateWithClause() (string, error) {
+	if len(qb.ctes) == 0 {
+		return "", nil
+	}
+	qry := "WITH "
+	for i, cte := range qb.ctes {
+		sub, err := qb.generateSubquery(cte.sub)
+		if err != nil {
+			return "", err
+		}
+		qry += cte.name + " AS (" + sub + ")"
+		if i < len(qb.ctes)-1 {
+			qry += ","
+		}
+	}
+	return qry + " ", nil
 }
 
 func (qb *Builder) generateSelectQry() (string, error) {
@@ -291,129 +2390,509 @@ func (qb *Builder) generateSelectQry() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	qry += qb.generateFromAndJoinClause()
-	whereClause, err := qb.generateWhereClause()
+	var conditions []string
+	if qb.legacyJoins {
+		var fromClause string
+		fromClause, conditions, err = qb.generateLegacyFromClause()
+		if err != nil {
+			return "", err
+		}
+		qry += fromClause
+	} else {
+		fromClause, err := qb.generateFromAndJoinClause()
+		if err != nil {
+			return "", err
+		}
+		qry += fromClause
+	}
+	whereClause, err := qb.generateWhereClause(conditions...)
+	if err != nil {
+		return "", err
+	}
+	qry += whereClause
+	groupByClause, err := qb.generateGroupByClause()
+	if err != nil {
+		return "", err
+	}
+	qry += groupByClause
+	unionClause, err := qb.generateUnionClause()
+	if err != nil {
+		return "", err
+	}
+	qry += unionClause
+	orderByClause, err := qb.generateOrderByClause()
+	if err != nil {
+		return "", err
+	}
+	qry += orderByClause
+	if qb.legacyPagination {
+		qry, err = qb.generateLegacyPagination(qry)
+	} else {
+		var limitClause string
+		limitClause, err = qb.generateLimitClause()
+		qry += limitClause
+	}
+	if err != nil {
+		return "", err
+	}
+	lockClause, err := qb.generateLockClause()
+	if err != nil {
+		return "", err
+	}
+	qry += lockClause
+	return qry, err
+}
+
+func (qb *Builder) generateDeleteQry() (string, error) {
+	qry, err := qb.generateDeleteClause()
+	if err != nil {
+		return "", err
+	}
+	fromClause, err := qb.generateFromAndJoinClause()
+	if err != nil {
+		return "", err
+	}
+	qry += fromClause
+	whereClause, err := qb.generateWhereClause()
+	if err != nil {
+		return "", err
+	}
+	qry += whereClause
+	returningClause, err := qb.generateReturningClause()
+	if err != nil {
+		return "", err
+	}
+	qry += returningClause
+	return qry, err
+}
+
+func (qb *Builder) generateUpdateQry() (string, error) {
+	qry, err := qb.generateUpdateClause()
+	if err != nil {
+		return "", err
+	}
+	fromClause, joinConditions, err := qb.generateUpdateFromClause()
+	if err != nil {
+		return "", err
+	}
+	qry += fromClause
+	whereClause, err := qb.generateWhereClause(joinConditions...)
+	if err != nil {
+		return "", err
+	}
+	qry += whereClause
+	returningClause, err := qb.generateReturningClause()
+	if err != nil {
+		return "", err
+	}
+	qry += returningClause
+	return qry, err
+}
+
+// Generates a TRUNCATE TABLE query. SQLite has no TRUNCATE so an unfiltered DELETE is
+// generated instead. Will return error if WHERE, JOIN or ORDER BY clauses are defined
+func (qb *Builder) generateTruncateQry() (string, error) {
+	if len(qb.criteria) > 0 || len(qb.joinTables) > 0 || len(qb.orderBy) > 0 {
+		return "", ErrTruncateWithClauses
+	}
+	if qb.db == SQLITE {
+		return "DELETE FROM " + qb.table, nil
+	}
+	return "TRUNCATE TABLE " + qb.table, nil
+}
+
+func (qb *Builder) generateInsertQry() (string, error) {
+	qry, err := qb.generateInsertClause()
+	if err != nil {
+		return "", err
+	}
+	returningClause, err := qb.generateReturningClause()
+	if err != nil {
+		return "", err
+	}
+	qry += returningClause
+	return qry, nil
+}
+
+// Generates the SELECT clause. Will return error if
+// a) values have been defined and their number is not equal to the number of columns,
+// unless the query selects all columns
+// b) DISTINCT ON is defined for a database engine that does not support it (all but
+// PostgreSQL) or together with DISTINCT
+func (qb *Builder) generateSelectClause() (string, error) {
+	columnCount := len(qb.columns)
+	if qb.paginationValues() != nil {
+		// the rnum column of the ROWNUM pagination
+		columnCount++
+	}
+	if len(qb.values) > 0 && !qb.selectAll && columnCount != len(qb.values) {
+		return "", NewBadColumnsValuesComboError(columnCount, len(qb.values))
+	}
+	qry := "SELECT "
+	if len(qb.distinctOn) > 0 {
+		if qb.distinct {
+			return "", ErrDistinctWithDistinctOn
+		}
+		if qb.db != POSTGRES {
+			return "", qb.unsupported("DISTINCT ON", ErrDBEngineDoesNotSupportDistinctOn)
+		}
+		qry += "DISTINCT ON (" + strings.Join(qb.distinctOn, ",") + ") "
+	} else if qb.distinct {
+		qry += "DISTINCT "
+	}
+	for i, column := range qb.columns {
+		if sub, ok := qb.selectSubqueries[column]; ok {
+			subQry, err := qb.generateSubquery(sub)
+			if err != nil {
+				return "", err
+			}
+			column = "(" + subQry + ")" + strings.TrimPrefix(column, selectedSubquery)
+		}
+		if function, ok := qb.selectFunctions[column]; ok {
+			expression, err := qb.generateSelectedFunction(function)
+			if err != nil {
+				return "", err
+			}
+			_, alias := splitAlias(column)
+			column = expression + " AS " + alias
+		}
+		qry += column
+		if i < len(qb.columns)-1 {
+			qry += ","
+		}
+	}
+	return qry, nil
+}
+
+// Generates a function selected with SelectStringAgg, SelectGreatest, SelectLeast or
+// SelectCoalesceDefault for the database engine of the query. Will return error if
+// GREATEST / LEAST are generated for SQL Server
+func (qb *Builder) generateSelectedFunction(function selectedFunction) (string, error) {
+	columns := strings.Join(function.columns, ",")
+	if function.name == "COALESCE" {
+		return "COALESCE(" + columns + "," + qb.addPlaceholders(len(function.values)) + ")", nil
+	}
+	if function.name != "STRING_AGG" {
+		switch qb.db {
+		case SQLSERVER:
+			return "", qb.unsupported(function.name, ErrDBEngineDoesNotSupportGreatestLeast)
+		case SQLITE:
+			if function.name == "GREATEST" {
+				return "MAX(" + columns + ")", nil
+			}
+			return "MIN(" + columns + ")", nil
+		}
+		return function.name + "(" + columns + ")", nil
+	}
+	separator := qb.quoteString(function.separator)
+	switch qb.db {
+	case MYSQL:
+		return "GROUP_CONCAT(" + columns + " SEPARATOR " + separator + ")", nil
+	case SQLITE:
+		return "GROUP_CONCAT(" + columns + "," + separator + ")", nil
+	case ORACLE:
+		return "LISTAGG(" + columns + "," + separator + ")", nil
+	}
+	return "STRING_AGG(" + columns + "," + separator + ")", nil
+}
+
+// Generates the RETURNING clause. Will return error if
+// a) values have been passed to Into and their number is not equal to the number of
+// returning columns, unless all the columns are returned with ReturningAll
+// b) the databse engine does not support the RETURNING clause (MySQL, SQL Server). The
+// column defined with LastInsertIDColumn is read from the result for MySQL, so no clause
+// is generated for it when it is the only returning column.
+func (qb *Builder) generateReturningClause() (string, error) {
+	if len(qb.returningColumns) == 0 || (qb.lastInsertID && qb.db == MYSQL && len(qb.returningColumns) == 1) {
+		return "", nil
+	}
+	if qb.db != POSTGRES && qb.db != ORACLE && qb.db != SQLITE {
+		return "", ErrDBEngineDoesNotSupportReturning
+	}
+	if !qb.returningAll && len(qb.returnValues) > 0 && len(qb.returningColumns) != len(qb.returnValues) {
+		return "", NewBadColumnsValuesComboError(len(qb.returningColumns), len(qb.returnValues))
+	}
+	qry := " RETURNING "
+	for i, column := range qb.returningColumns {
+		qry += column
+		if i < len(qb.returningColumns)-1 {
+			qry += ","
+		}
+	}
+	return qry, nil
+}
+
+// Generates the FROM and join clauses
+func (qb *Builder) generateFromAndJoinClause() (string, error) {
+	table, err := qb.generateFromTable()
+	if err != nil {
+		return "", err
+	}
+	hints, err := qb.generateIndexHints()
+	if err != nil {
+		return "", err
+	}
+	joinClause, err := qb.generateJoinClause()
 	if err != nil {
 		return "", err
 	}
-	qry += whereClause
-	qry += qb.generateOrderByClause()
-	qry += qb.generateLimitClause()
-	return qry, err
+	return " FROM " + table + hints + joinClause, nil
 }
 
-func (qb *Builder) generateDeleteQry() (string, error) {
-	qry := qb.generateDeleteClause()
-	qry += qb.generateFromAndJoinClause()
-	whereClause, err := qb.generateWhereClause()
-	if err != nil {
-		return "", err
+// Generates the table of the FROM clause, which is the subquery of a derived table defined
+// with FromSubquery followed by its alias
+func (qb *Builder) generateFromTable() (string, error) {
+	if qb.fromSub == nil {
+		return qb.tableWithAlias(), nil
 	}
-	qry += whereClause
-	returningClause, err := qb.generateReturningClause()
+	sub, err := qb.generateSubquery(qb.fromSub)
 	if err != nil {
 		return "", err
 	}
-	qry += returningClause
-	return qry, err
+	return "(" + sub + ") " + qb.table, nil
 }
 
-func (qb *Builder) generateUpdateQry() (string, error) {
-	qry, err := qb.generateUpdateClause()
-	if err != nil {
-		return "", err
+// Generates the index hints of the table of the query. Will return error if the database
+// engine does not support index hints (all but MySQL)
+func (qb *Builder) generateIndexHints() (string, error) {
+	if len(qb.indexHints) == 0 {
+		return "", nil
 	}
-	whereClause, err := qb.generateWhereClause()
-	if err != nil {
-		return "", err
+	if qb.db != MYSQL {
+		return "", qb.unsupported("index hints", ErrDBEngineDoesNotSupportIndexHints)
 	}
-	qry += whereClause
-	returningClause, err := qb.generateReturningClause()
-	if err != nil {
-		return "", err
+	qry := ""
+	for _, h := range qb.indexHints {
+		qry += " " + h.hint + " (" + h.index + ")"
 	}
-	qry += returningClause
-	return qry, err
+	return qry, nil
 }
 
-func (qb *Builder) generateInsertQry() (string, error) {
-	qry, err := qb.generateInsertClause()
-	if err != nil {
-		return "", err
+// Generates the FROM clause of the legacy Oracle join syntax, along with the join
+// conditions that have to be added to the WHERE clause. Will return error if
+// a) the database engine is not Oracle
+// b) a join is a FULL join or a join with USING
+func (qb *Builder) generateLegacyFromClause() (string, []string, error) {
+	if qb.db != ORACLE {
+		return "", nil, qb.unsupported("legacy Oracle joins", ErrDBEngineDoesNotSupportLegacyJoins)
 	}
-	returningClause, err := qb.generateReturningClause()
+	table, err := qb.generateFromTable()
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+	tables := []string{table}
+	var conditions []string
+	for _, joinTable := range qb.joinTables {
+		if len(joinTable.using) > 0 {
+			return "", nil, ErrLegacyJoinNotSupported
+		}
+		tables = append(tables, joinTable.tableWithAlias())
+		if joinTable.condition != "" {
+			joinType := strings.ToUpper(joinTable.joinType)
+			if joinType != "" && joinType != "INNER" {
+				return "", nil, ErrLegacyJoinNotSupported
+			}
+			condition, err := qb.generateWhereJoinCondition(joinTable)
+			if err != nil {
+				return "", nil, err
+			}
+			conditions = append(conditions, condition)
+			continue
+		}
+		if joinTable.column == "" {
+			continue
+		}
+		column, fkey := joinTable.column, joinTable.fkey
+		joined := joinTable.alias
+		if joined == "" {
+			joined = joinTable.table
+		}
+		// The column of the joined table is column, unless only fkey belongs to it
+		joinedIsFkey := strings.HasPrefix(fkey, joined+".") && !strings.HasPrefix(column, joined+".")
+		switch strings.TrimSuffix(strings.ToUpper(joinTable.joinType), " OUTER") {
+		case "", "INNER":
+		case "LEFT":
+			if joinedIsFkey {
+				fkey += "(+)"
+			} else {
+				column += "(+)"
+			}
+		case "RIGHT":
+			if joinedIsFkey {
+				column += "(+)"
+			} else {
+				fkey += "(+)"
+			}
+		default:
+			return "", nil, ErrLegacyJoinNotSupported
+		}
+		conditions = append(conditions, column+"="+fkey)
+	}
+	return " FROM " + strings.Join(tables, ","), conditions, nil
+}
+
+// Generates the join clause. Joins without a column, a condition or USING columns, like
+// CROSS JOIN, have no condition. Will return error if the placeholders of a join condition
+// do not match its values
+func (qb *Builder) generateJoinClause() (string, error) {
+	qry := ""
+	for _, joinTable := range qb.joinTables {
+		qry += " " + joinTable.joinType +
+			" JOIN " +
+			joinTable.tableWithAlias()
+		if len(joinTable.using) > 0 {
+			qry += " USING (" + strings.Join(joinTable.using, ",") + ")"
+			continue
+		}
+		condition, err := qb.generateJoinCondition(joinTable)
+		if err != nil {
+			return "", err
+		}
+		if condition != "" {
+			qry += " ON " + condition
+		}
 	}
-	qry += returningClause
 	return qry, nil
 }
 
-// Generates the SELECT clause. Will return error if the number of values is not equal
-// to the number of columns
-func (qb *Builder) generateSelectClause() (string, error) {
-	if len(qb.columns) != len(qb.values) {
-		return "", NewBadColumnsValuesComboError(len(qb.columns), len(qb.values))
+// Generates the ON condition of a join, which is empty for joins without a column or a
+// condition
+func (qb *Builder) generateJoinCondition(joinTable join) (string, error) {
+	if joinTable.condition != "" {
+		return qb.translatePlaceholders(joinTable.condition, len(joinTable.values))
 	}
-	qry := "SELECT "
-	for i, column := range qb.columns {
-		qry += column
-		if i < len(qb.columns)-1 {
-			qry += ","
+	if joinTable.column == "" {
+		return "", nil
+	}
+	return joinTable.column + "=" + joinTable.fkey, nil
+}
+
+// Generates the condition of a join that is added to the WHERE clause, wrapping a condition
+// defined with JoinCond in parentheses, since it may contain an OR
+func (qb *Builder) generateWhereJoinCondition(joinTable join) (string, error) {
+	condition, err := qb.generateJoinCondition(joinTable)
+	if err != nil || joinTable.condition == "" {
+		return condition, err
+	}
+	return "(" + condition + ")", nil
+}
+
+// Generates the FROM clause of an update with joins for PostgreSQL and SQLite, along with
+// the join conditions that have to be added to the WHERE clause, since UPDATE ... FROM has
+// no ON. MySQL joins are generated in the UPDATE clause instead. Will return error if
+// a) the database engine does not support updates with joins (Oracle, SQL Server)
+// b) a join is not an INNER or CROSS join with an ON condition, since the FROM form
+// can only express inner joins
+// c) the placeholders of a join condition do not match its values
+func (qb *Builder) generateUpdateFromClause() (string, []string, error) {
+	if len(qb.joinTables) == 0 || qb.db == MYSQL {
+		return "", nil, nil
+	}
+	if qb.db != POSTGRES && qb.db != SQLITE {
+		return "", nil, qb.unsupported("UPDATE with joins", ErrDBEngineDoesNotSupportUpdateJoin)
+	}
+	var tables, conditions []string
+	for _, joinTable := range qb.joinTables {
+		joinType := strings.ToUpper(joinTable.joinType)
+		if (joinType != "INNER" && joinType != "CROSS") || len(joinTable.using) > 0 {
+			return "", nil, ErrUpdateFromRequiresInnerJoin
+		}
+		tables = append(tables, joinTable.tableWithAlias())
+		condition, err := qb.generateWhereJoinCondition(joinTable)
+		if err != nil {
+			return "", nil, err
+		}
+		if condition != "" {
+			conditions = append(conditions, condition)
 		}
 	}
-	return qry, nil
+	return " FROM " + strings.Join(tables, ","), conditions, nil
 }
 
-// Generates the RETURNING clause. Will return error if
-// a) the number of values is not equal to the number of returning columns
-// b) the databse engine does not support the RETURNING clause (MySQL, SQLite)
-func (qb *Builder) generateReturningClause() (string, error) {
-	if len(qb.returningColumns) == 0 {
+// Generates the WHERE clause. Additional conditions, like the join conditions of an update,
+// are added before the criteria, which are wrapped in parentheses if they contain an OR.
+// Will return error if a comparison operator is invalid
+func (qb *Builder) generateWhereClause(conditions ...string) (string, error) {
+	if len(qb.criteria) == 0 && len(conditions) == 0 {
 		return "", nil
 	}
-	if qb.db != POSTGRES && qb.db != ORACLE {
-		return "", ErrDBEngineDoesNotSupportReturning
+	if len(qb.criteria) > 0 {
+		criteria, err := qb.generateCriteria(qb.criteria)
+		if err != nil {
+			return "", err
+		}
+		if len(conditions) > 0 && hasOrCriterion(qb.criteria) {
+			criteria = "(" + criteria + ")"
+		}
+		conditions = append(conditions, criteria)
 	}
-	if len(qb.returningColumns) != len(qb.returnValues) {
-		return "", NewBadColumnsValuesComboError(len(qb.returningColumns), len(qb.returnValues))
+	return " WHERE " + strings.Join(conditions, " AND "), nil
+}
+
+// Generates a row value IN criterion, like (a,b) IN ((?,?),(?,?)). Will return error if
+// a) the database engine does not support row values (SQL Server)
+// b) there are no rows, unless EmptyInAsFalse has been called
+func (qb *Builder) generateRowIn(criterion criterion) (string, error) {
+	if qb.db == SQLSERVER {
+		return "", qb.unsupported("row value IN", ErrDBEngineDoesNotSupportRowValues)
 	}
-	qry := " RETURNING "
-	for i, column := range qb.returningColumns {
-		qry += column
-		if i < len(qb.returningColumns)-1 {
-			qry += ","
+	if len(criterion.values) == 0 {
+		if !qb.emptyInAsFalse {
+			return "", ErrEmptyInValues
+		}
+		return "1=0", nil
+	}
+	rows := make([]string, len(criterion.values)/len(criterion.rowColumns))
+	for i := range rows {
+		rows[i] = "(" + qb.addPlaceholders(len(criterion.rowColumns)) + ")"
+	}
+	return "(" + strings.Join(criterion.rowColumns, ",") + ") IN (" + strings.Join(rows, ",") + ")", nil
+}
+
+// Generates the GROUP BY clause along with the HAVING clause. Will return error if
+// a) HAVING criteria are defined without GROUP BY columns
+// b) a comparison operator of the HAVING criteria is invalid
+func (qb *Builder) generateGroupByClause() (string, error) {
+	if len(qb.groupBy) == 0 {
+		if len(qb.having) > 0 {
+			return "", ErrHavingWithoutGroupBy
 		}
+		return "", nil
+	}
+	qry := " GROUP BY " + strings.Join(qb.groupBy, ",")
+	if len(qb.having) > 0 {
+		having, err := qb.generateCriteria(qb.having)
+		if err != nil {
+			return "", err
+		}
+		qry += " HAVING " + having
 	}
 	return qry, nil
 }
 
-// Generates the join clause
-func (qb *Builder) generateFromAndJoinClause() string {
-	qry := " FROM " + qb.table
-	for _, joinTable := range qb.joinTables {
-		qry += " " + joinTable.joinType +
-			" JOIN " +
-			joinTable.table +
-			" ON " +
-			joinTable.column +
-			"=" +
-			joinTable.fkey
+// Checks if any of a list of criteria is joined with OR
+func hasOrCriterion(criteria []criterion) bool {
+	for _, criterion := range criteria {
+		if criterion.or {
+			return true
+		}
 	}
-	return qry
+	return false
 }
 
-// Generates the WHERE clause. Will return error if a comparison operator is invalid
-func (qb *Builder) generateWhereClause() (string, error) {
-	if len(qb.criteria) == 0 {
-		return "", nil
+// Generates a list of criteria joined by AND / OR. Nested groups are generated
+// recursively and wrapped in parentheses. Will return error if the first criterion
+// of the list is an OR, a group is empty or a comparison operator is invalid
+func (qb *Builder) generateCriteria(criteria []criterion) (string, error) {
+	if len(criteria) == 0 {
+		return "", ErrEmptyWhereGroup
 	}
-	qry := " WHERE "
-	for ci, criterion := range qb.criteria {
+	qry := ""
+	for ci, criterion := range criteria {
 		if ci == 0 && criterion.or {
 			return "", ErrFirstCriterionIsOr
 		}
-		if ci != 0 && ci < len(qb.criteria) {
+		if ci != 0 && ci < len(criteria) {
 			switch criterion.or {
 			case true:
 				qry += " OR "
@@ -421,64 +2900,394 @@ func (qb *Builder) generateWhereClause() (string, error) {
 				qry += " AND "
 			}
 		}
+		if criterion.group != nil {
+			group, err := qb.generateCriteria(criterion.group)
+			if err != nil {
+				return "", err
+			}
+			qry += "(" + group + ")"
+			continue
+		}
+		if criterion.sub != nil {
+			sub, err := qb.generateSubquery(criterion.sub)
+			if err != nil {
+				return "", err
+			}
+			if criterion.column != "" {
+				qry += criterion.column + " "
+			}
+			qry += criterion.operator + " (" + sub + ")"
+			continue
+		}
+		if criterion.raw {
+			// A raw fragment may contain an OR, so it is always wrapped in parentheses
+			fragment, err := qb.translatePlaceholders(criterion.column, len(criterion.values))
+			if err != nil {
+				return "", err
+			}
+			qry += "(" + fragment + ")"
+			continue
+		}
+		if criterion.literal {
+			qry += criterion.column + "=" + qb.boolLiteral(criterion.values[0].(bool))
+			continue
+		}
+		if criterion.column2 != "" {
+			qry += qb.prefixColumn(criterion.column) + criterion.operator + qb.prefixColumn(criterion.column2)
+			continue
+		}
+		if criterion.json {
+			if qb.db != POSTGRES {
+				return "", qb.unsupported("JSON operators", ErrDBEngineDoesNotSupportJSONOperators)
+			}
+			if criterion.jsonKey != "" {
+				criterion.column += "->>" + qb.quoteString(criterion.jsonKey)
+			}
+			qry += qb.generateComparison(criterion)
+			continue
+		}
+		if len(criterion.rowColumns) > 0 {
+			rowIn, err := qb.generateRowIn(criterion)
+			if err != nil {
+				return "", err
+			}
+			qry += rowIn
+			continue
+		}
 		if !qb.operatorIsValid(criterion.operator) {
 			return "", NewInvalidOperatorError(criterion.operator)
 		}
-		qry += criterion.column
-		if criterion.operator == "BETWEEN" || criterion.operator == "IN" || criterion.operator == "LIKE" {
-			qry += " "
+		if err := checkOperatorValues(criterion.operator, len(criterion.values)); err != nil {
+			return "", err
+		}
+		if criterion.operator == "ILIKE" && qb.db != POSTGRES {
+			return "", qb.unsupported("ILIKE", ErrDBEngineDoesNotSupportILike)
+		}
+		if criterion.quantifier != "" && qb.db != POSTGRES {
+			return "", qb.unsupported(criterion.quantifier, ErrDBEngineDoesNotSupportAnyAll)
+		}
+		if (criterion.operator == "IN" || criterion.operator == "NOT IN") && len(criterion.values) == 0 {
+			if !qb.emptyInAsFalse {
+				return "", ErrEmptyInValues
+			}
+			if criterion.operator == "IN" {
+				qry += "1=0"
+			} else {
+				qry += "1=1"
+			}
+			continue
 		}
+		qry += qb.generateComparison(criterion)
+	}
+	return qry, nil
+}
+
+// Generates the comparison of a criterion with its operator and placeholders, as defined in
+// the operators table
+func (qb *Builder) generateComparison(criterion criterion) string {
+	spec := operatorSpecOf(criterion.operator)
+	qry := criterion.column
+	if spec.spaced {
+		qry += " " + criterion.operator + " "
+	} else {
 		qry += criterion.operator
-		switch {
-		case criterion.operator == "LIKE":
-			qry += " " + qb.addPlaceholder()
-		case criterion.operator == "BETWEEN":
-			qry += " " + qb.addPlaceholder() + " AND " + qb.addPlaceholder()
-		case criterion.operator == "IN":
-			qry += " (" + qb.addPlaceholder() + strings.Repeat(","+qb.addPlaceholder(), len(criterion.values)-1) + ")"
-		default:
-			qry += qb.addPlaceholder()
+	}
+	switch {
+	case criterion.quantifier != "":
+		qry += criterion.quantifier + "(" + qb.addPlaceholder() + ")"
+	case spec.placeholders == rangePlaceholders:
+		qry += qb.addPlaceholder() + " AND " + qb.addPlaceholder()
+	case spec.placeholders == listPlaceholders:
+		qry += "(" + qb.addPlaceholders(len(criterion.values)) + ")"
+	default:
+		qry += qb.addPlaceholder()
+	}
+	if criterion.escape && qb.db != MYSQL && qb.db != POSTGRES {
+		qry += ` ESCAPE '\'`
+	}
+	return qry
+}
+
+// Returns the rendering of an operator as defined in the operators table. Operators added
+// with AllowOperators have a single placeholder and are spaced if they are words, like
+// SIMILAR TO, so they are not joined to the column and the placeholder.
+func operatorSpecOf(operator string) operatorSpec {
+	if spec, ok := operators[operator]; ok {
+		return spec
+	}
+	spaced := strings.IndexFunc(operator, func(r rune) bool {
+		return r >= 'A' && r <= 'Z'
+	}) >= 0
+	return operatorSpec{spaced: spaced, placeholders: singlePlaceholder}
+}
+
+// Checks that the number of values of a criterion matches the placeholders of its operator.
+// Will return error if
+// a) an operator with range placeholders, like BETWEEN, does not have exactly two values
+// b) an operator with a single placeholder does not have exactly one value
+//
+// Operators with list placeholders, like IN, can have any number of values, since an empty
+// list is handled when the query is generated.
+func checkOperatorValues(operator string, valueCount int) error {
+	switch operators[operator].placeholders {
+	case rangePlaceholders:
+		if valueCount != 2 {
+			return ErrBadBetweenValues
 		}
+	case singlePlaceholder:
+		if valueCount != 1 {
+			return ErrBadOperatorValues
+		}
+	}
+	return nil
+}
+
+// Generates the query of a subquery, continuing the placeholder numbering of qb. The
+// subquery is generated for the database engine of qb, on a copy of its builder, so the
+// builder passed by the caller is left unchanged. Will return error if the subquery is not
+// a SELECT query
+func (qb *Builder) generateSubquery(sub *Builder) (string, error) {
+	if sub.queryType != selectQry {
+		return "", ErrNotSelectQuery
+	}
+	copied := *sub
+	copied.db = qb.db
+	copied.placeholderCount = qb.placeholderCount
+	qry, err := copied.generateQuery()
+	qb.placeholderCount = copied.placeholderCount
+	return qry, err
+}
+
+// Generates the UNION clauses. Will return error if a combined query does not select the
+// same number of columns as this query
+func (qb *Builder) generateUnionClause() (string, error) {
+	qry := ""
+	for _, union := range qb.unions {
+		if len(union.sub.columns) != len(qb.columns) {
+			return "", NewUnionColumnsMismatchError(len(qb.columns), len(union.sub.columns))
+		}
+		sub, err := qb.generateSubquery(union.sub)
+		if err != nil {
+			return "", err
+		}
+		if union.all {
+			qry += " UNION ALL "
+		} else {
+			qry += " UNION "
+		}
+		qry += sub
 	}
 	return qry, nil
 }
 
-// Generates the ORDER BY clause
-func (qb *Builder) generateOrderByClause() string {
+// Generates the ORDER BY clause. Will return error if a NULL placement is defined for a
+// database engine that does not support it (MySQL, SQL Server)
+func (qb *Builder) generateOrderByClause() (string, error) {
 	if len(qb.orderBy) == 0 {
-		return ""
+		return "", nil
 	}
 	qry := " ORDER BY "
 	for ci, order := range qb.orderBy {
-		qry += order.column
+		order.column = qb.orderColumn(order)
+		if len(order.values) > 0 {
+			qry += qb.generateFieldOrder(order)
+		} else {
+			qry += order.column
+		}
 		switch {
+		case order.raw:
 		case order.direction == descending:
 			qry += " DESC"
 		default:
 			qry += " ASC"
 		}
+		if order.nulls != nullsDefault && qb.db != POSTGRES && qb.db != ORACLE && qb.db != SQLITE {
+			return "", qb.unsupported("NULLS FIRST / NULLS LAST", ErrDBEngineDoesNotSupportNullsOrder)
+		}
+		switch order.nulls {
+		case nullsFirst:
+			qry += " NULLS FIRST"
+		case nullsLast:
+			qry += " NULLS LAST"
+		}
 		if ci < len(qb.orderBy)-1 {
 			qry += ","
 		}
 	}
-	return qry
+	return qry, nil
+}
+
+// Returns the column of an order prefixed like in Select, so the columns of the table are
+// not ambiguous in queries with joins. Raw and ordinal orders, the aliases of selected
+// expressions and the orders of queries combined with UNION, which can only refer to the
+// result columns, are returned unchanged.
+func (qb *Builder) orderColumn(order ordering) string {
+	if order.raw || order.ordinal || len(qb.unions) > 0 {
+		return order.column
+	}
+	if qb.isSelectedAlias(order.column) {
+		return order.column
+	}
+	return qb.prefixColumn(order.column)
+}
+
+// Checks if a column is the alias of a selected column or expression
+func (qb *Builder) isSelectedAlias(column string) bool {
+	for _, selected := range qb.columns {
+		if _, alias := splitAlias(selected); alias == column {
+			return true
+		}
+	}
+	return false
+}
+
+// Generates the expression of an order defined with OrderByField, which is the FIELD
+// function for MySQL and an equivalent CASE expression for the other database engines
+func (qb *Builder) generateFieldOrder(order ordering) string {
+	if qb.db == MYSQL {
+		return "FIELD(" + order.column + "," + qb.addPlaceholders(len(order.values)) + ")"
+	}
+	qry := "CASE " + order.column
+	for i := range order.values {
+		qry += " WHEN " + qb.addPlaceholder() + " THEN " + strconv.Itoa(i+1)
+	}
+	return qry + " ELSE 0 END"
+}
+
+// Returns the boolean literal of the database engine of the builder
+func (qb *Builder) boolLiteral(value bool) string {
+	switch {
+	case qb.db == POSTGRES && value:
+		return "TRUE"
+	case qb.db == POSTGRES:
+		return "FALSE"
+	case value:
+		return "1"
+	}
+	return "0"
 }
 
-func (qb *Builder) generateLimitClause() string {
-	if qb.limit == 0 {
-		return ""
+// Generates the LIMIT clause, as LIMIT n OFFSET m, since the LIMIT m,n form of MySQL and
+// SQLite is easily misread and PostgreSQL does not support it. Oracle and SQL Server have no
+// LIMIT so the OFFSET m ROWS FETCH NEXT n ROWS ONLY form is used instead, which for SQL
+// Server requires the query to have an ORDER BY clause. Will return error if
+// a) an offset is set without a limit, which would be silently dropped, unless LimitZero
+// has been called
+// b) a LIMIT 0 is set for SQL Server, which does not allow fetching zero rows
+// c) a limit is set for SQL Server on a query without an ORDER BY clause
+func (qb *Builder) generateLimitClause() (string, error) {
+	if qb.withTies {
+		return qb.generateWithTiesClause()
+	}
+	if !qb.hasLimit {
+		if qb.offset > 0 {
+			return "", ErrOffsetWithoutLimit
+		}
+		return "", nil
+	}
+	if qb.db == SQLSERVER {
+		if qb.limit == 0 {
+			return "", qb.unsupported("LIMIT 0", ErrDBEngineDoesNotSupportLimitZero)
+		}
+		if len(qb.orderBy) == 0 {
+			return "", ErrOffsetFetchWithoutOrderBy
+		}
+		return fmt.Sprintf(" OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", qb.offset, qb.limit), nil
+	}
+	if qb.db == ORACLE {
+		qry := ""
+		if qb.offset > 0 {
+			qry += fmt.Sprintf(" OFFSET %d ROWS", qb.offset)
+		}
+		return qry + fmt.Sprintf(" FETCH NEXT %d ROWS ONLY", qb.limit), nil
 	}
 	qry := fmt.Sprintf(" LIMIT %d", qb.limit)
 	if qb.offset > 0 {
-		qry += fmt.Sprintf(",%d", qb.offset)
+		qry += fmt.Sprintf(" OFFSET %d", qb.offset)
 	}
-	return qry
+	return qry, nil
+}
+
+// Wraps a select query in the ROWNUM pagination of Oracle versions before 12c. Will return
+// error if the database engine is not Oracle
+func (qb *Builder) generateLegacyPagination(qry string) (string, error) {
+	if qb.db != ORACLE {
+		return "", qb.unsupported("ROWNUM pagination", ErrDBEngineDoesNotSupportLegacyPagination)
+	}
+	if !qb.hasLimit {
+		return qry, nil
+	}
+	qry = "SELECT * FROM (SELECT a.*,ROWNUM rnum FROM (" + qry + ") a WHERE ROWNUM<=" + qb.addPlaceholder()
+	return qry + ") WHERE rnum>" + qb.addPlaceholder(), nil
+}
+
+// Returns the values bound to the bounds of the ROWNUM pagination of Oracle versions before
+// 12c, if the query is paginated with it
+func (qb *Builder) paginationValues() []interface{} {
+	if !qb.legacyPagination || !qb.hasLimit || qb.db != ORACLE || qb.queryType != selectQry {
+		return nil
+	}
+	return []interface{}{qb.offset + qb.limit, qb.offset}
+}
+
+// Generates the OFFSET ... FETCH FIRST ... ROWS WITH TIES clause. Will return error if
+// a) the database engine does not support WITH TIES (all but PostgreSQL and Oracle)
+// b) the query has no ORDER BY clause or no limit
+func (qb *Builder) generateWithTiesClause() (string, error) {
+	if qb.db != POSTGRES && qb.db != ORACLE {
+		return "", qb.unsupported("WITH TIES", ErrDBEngineDoesNotSupportWithTies)
+	}
+	if len(qb.orderBy) == 0 {
+		return "", ErrWithTiesWithoutOrderBy
+	}
+	if !qb.hasLimit {
+		return "", ErrWithTiesWithoutLimit
+	}
+	qry := ""
+	if qb.offset > 0 {
+		qry += fmt.Sprintf(" OFFSET %d ROWS", qb.offset)
+	}
+	return qry + fmt.Sprintf(" FETCH FIRST %d ROWS WITH TIES", qb.limit), nil
+}
+
+// Uppercases an operator and maps aliases to their standard SQL form, like != to <>
+func normalizeOperator(operator string) string {
+	operator = strings.ToUpper(operator)
+	if operator == "!=" {
+		return "<>"
+	}
+	return operator
+}
+
+// Generates the row locking clause. Will return error if the database engine does not
+// support the lock (SQLite, SQL Server, and Oracle for FOR SHARE)
+func (qb *Builder) generateLockClause() (string, error) {
+	if qb.lock == noLock {
+		return "", nil
+	}
+	qry := " FOR UPDATE"
+	if qb.lock == lockForShare {
+		qry = " FOR SHARE"
+	}
+	if qb.db == SQLITE || qb.db == SQLSERVER || (qb.db == ORACLE && qb.lock == lockForShare) {
+		return "", qb.unsupported(qry[1:], ErrDBEngineDoesNotSupportRowLock)
+	}
+	switch qb.lockWait {
+	case skipLocked:
+		qry += " SKIP LOCKED"
+	case noWait:
+		qry += " NOWAIT"
+	}
+	return qry, nil
 }
 
 // Checks if a comparison operator is valid
 func (qb *Builder) operatorIsValid(operator string) bool {
-	for _, o := range strings.Split(validOperators, "/") {
-		if operator == o {
+	if _, ok := operators[operator]; ok {
+		return true
+	}
+	for _, allowed := range qb.allowedOperators {
+		if operator == allowed {
 			return true
 		}
 	}
@@ -506,6 +3315,26 @@ func NewDelete(tableName string) *Builder {
 	}
 }
 
+// Define the tables, or their aliases, the rows of a delete with joins will be deleted
+// from. This generates the MySQL multiple table form, like
+//   - NewDelete("table1").DeleteFrom("table1").Join("INNER", "table2", "table2.table1_id", "table1.id")
+//     will produce DELETE table1 FROM table1 INNER JOIN table2 ON table2.table1_id=table1.id
+//
+// Generating the query for any other database engine will return an error.
+func (qb *Builder) DeleteFrom(tables ...string) *Builder {
+	qb.deleteTables = append(qb.deleteTables, tables...)
+	return qb
+}
+
+// Creates a new query builder for TRUNCATE TABLE, which removes all the rows of the table
+// passed as the tableName parameter.
+func NewTruncate(tableName string) *Builder {
+	return &Builder{
+		queryType: truncateQry,
+		table:     tableName,
+	}
+}
+
 func (qb *Builder) generateInsertClause() (string, error) {
 	if len(qb.columns) != len(qb.values) {
 		return "", NewBadColumnsValuesComboError(len(qb.columns), len(qb.values))
@@ -518,8 +3347,12 @@ func (qb *Builder) generateInsertClause() (string, error) {
 		}
 	}
 	qry += ") VALUES ("
-	for i := range qb.values {
-		qry += qb.addPlaceholder()
+	for i, value := range qb.values {
+		placeholder, err := qb.generateValue(value)
+		if err != nil {
+			return "", err
+		}
+		qry += placeholder
 		if i < len(qb.values)-1 {
 			qry += ","
 		}
@@ -532,9 +3365,21 @@ func (qb *Builder) generateUpdateClause() (string, error) {
 	if len(qb.columns) != len(qb.values) {
 		return "", NewBadColumnsValuesComboError(len(qb.columns), len(qb.values))
 	}
-	qry := "UPDATE " + qb.table + " SET "
+	qry := "UPDATE " + qb.tableWithAlias()
+	if qb.db == MYSQL {
+		joinClause, err := qb.generateJoinClause()
+		if err != nil {
+			return "", err
+		}
+		qry += joinClause
+	}
+	qry += " SET "
 	for i, column := range qb.columns {
-		qry += column + "=" + qb.addPlaceholder()
+		value, err := qb.generateValue(qb.values[i])
+		if err != nil {
+			return "", err
+		}
+		qry += column + "=" + value
 		if i < len(qb.columns)-1 {
 			qry += ","
 		}
@@ -542,18 +3387,90 @@ func (qb *Builder) generateUpdateClause() (string, error) {
 	return qry, nil
 }
 
-func (qb *Builder) generateDeleteClause() string {
+// Generates the placeholder of an insert / update value, or the expression of a value
+// defined with SetRaw
+func (qb *Builder) generateValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case rawExpression:
+		return qb.translatePlaceholders(v.expression, len(v.values))
+	case sqlKeyword:
+		if v == Default && qb.db == SQLITE {
+			return "", qb.unsupported("DEFAULT", ErrDBEngineDoesNotSupportDefault)
+		}
+		if v == CurrentTimestamp {
+			return qb.currentTimestamp(), nil
+		}
+		return string(v), nil
+	default:
+		return qb.addPlaceholder(), nil
+	}
+}
+
+// Returns the function of the current timestamp of the database engine of the builder
+func (qb *Builder) currentTimestamp() string {
+	switch qb.db {
+	case MYSQL:
+		return "NOW()"
+	case POSTGRES:
+		return "now()"
+	case ORACLE:
+		return "SYSDATE"
+	}
+	return string(CurrentTimestamp)
+}
+
+// Generates the DELETE clause. Will return error if the tables to delete from are defined
+// for a database engine that does not support multiple table deletes (all but MySQL)
+func (qb *Builder) generateDeleteClause() (string, error) {
 	qry := "DELETE"
-	return qry
+	if len(qb.deleteTables) > 0 {
+		if qb.db != MYSQL {
+			return "", qb.unsupported("DELETE from multiple tables", ErrDBEngineDoesNotSupportMultiTableDelete)
+		}
+		qry += " " + strings.Join(qb.deleteTables, ",")
+	}
+	return qry, nil
+}
+
+// Replaces each ? of a raw SQL fragment with a placeholder of the database engine. Will
+// return error if the number of ? is not equal to the number of values bound to them
+func (qb *Builder) translatePlaceholders(fragment string, valueCount int) (string, error) {
+	if count := strings.Count(fragment, "?"); count != valueCount {
+		return "", NewBadPlaceholdersValuesComboError(count, valueCount)
+	}
+	parts := strings.Split(fragment, "?")
+	qry := parts[0]
+	for _, part := range parts[1:] {
+		qry += qb.addPlaceholder() + part
+	}
+	return qry, nil
+}
+
+// Adds count comma separated placeholders
+func (qb *Builder) addPlaceholders(count int) string {
+	placeholders := make([]string, count)
+	for i := range placeholders {
+		placeholders[i] = qb.addPlaceholder()
+	}
+	return strings.Join(placeholders, ",")
 }
 
 func (qb *Builder) addPlaceholder() string {
 	qb.placeholderCount += 1
-	switch qb.db {
+	return Placeholder(qb.db, qb.placeholderCount)
+}
+
+// Returns the placeholder of the nth value of a query, starting from 1, for a database
+// engine, as generated by the builder. These are $n for PostgreSQL, :n for Oracle, @pn for
+// SQL Server and ? for MySQL and SQLite, which are not numbered.
+func Placeholder(db database, n int) string {
+	switch db {
 	case POSTGRES:
-		return fmt.Sprintf("$%d", qb.placeholderCount)
+		return fmt.Sprintf("$%d", n)
 	case ORACLE:
-		return fmt.Sprintf(":%d", qb.placeholderCount)
+		return fmt.Sprintf(":%d", n)
+	case SQLSERVER:
+		return fmt.Sprintf("@p%d", n)
 	default:
 		return "?"
 	}
@@ -1,6 +1,7 @@
 package sqlquerybob
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -139,7 +140,7 @@ func TestItCreatesASimpleSQLStatementWithLimitAndOffset(t *testing.T) {
 	expected := "SELECT table1.field1,table1.field2,table1.field3,table1.field4" +
 		" FROM table1" +
 		" WHERE table1.field1=?" +
-		" LIMIT 10,50"
+		" LIMIT 10 OFFSET 50"
 	assert.Nil(err)
 	assert.Equal(expected, qry)
 	assert.Equal([]any{&d.field1, &d.field2, &d.field3, &d.field4}, qb.Values())
@@ -327,7 +328,7 @@ func TestItCreatesASimpleDeleteStatement(t *testing.T) {
 }
 
 func TestItReturnsAnErrorIfDeleteWhereClauseInvalid(t *testing.T) {
-	qb := NewDelete("table1").Where("table1.field1", "!=", "value1")
+	qb := NewDelete("table1").Where("table1.field1", "==", "value1")
 	qry, err := qb.GenerateQuery()
 
 	assert := assert.New(t)
@@ -356,7 +357,7 @@ func TestItReturnsAnErrorIfAnUpdateWhereClauseIsInvalid(t *testing.T) {
 	qb := NewUpdate("table1").
 		Set("field1", "field2", "field3", "field4").
 		To("value1", 2, 5, "value4").
-		Where("table1.id", "!=", 10)
+		Where("table1.id", "==", 10)
 
 	qry, err := qb.GenerateQuery()
 
@@ -429,6 +430,22 @@ func TestItCreatesAnInsertStatementForPostgresWithReturningClause(t *testing.T)
 	assert.Equal([]any{&d.id, &d.field1}, qb.ReturningValues())
 }
 
+func TestItPrefixesTheReturningColumnsOfAnInsertWithTheTableInsteadOfTheAlias(t *testing.T) {
+	var id int
+	qry, err := NewInsert("users").
+		ForPostgres().
+		As("u").
+		Set("name").
+		To("name1").
+		Returning("id").
+		Into(&id).
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("INSERT INTO users (name) VALUES ($1) RETURNING users.id", qry)
+}
+
 func TestItCreatesAnInsertStatementForOracleWithReturningClause(t *testing.T) {
 	var d struct {
 		id     int
@@ -451,6 +468,28 @@ func TestItCreatesAnInsertStatementForOracleWithReturningClause(t *testing.T) {
 	assert.Equal([]any{&d.id, &d.field1}, qb.ReturningValues())
 }
 
+func TestItCreatesAnInsertStatementForSQLiteWithReturningClause(t *testing.T) {
+	var d struct {
+		id     int
+		field1 string
+	}
+	qb := NewInsert("table1").
+		ForDatabase(SQLITE).
+		Set("field1", "field2").
+		To("value1", 2).
+		Returning("id", "field1").
+		Into(&d.id, &d.field1)
+
+	qry, err := qb.GenerateQuery()
+	assert := assert.New(t)
+	expected := "INSERT INTO table1 (field1,field2) VALUES (?,?)" +
+		" RETURNING table1.id,table1.field1"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", 2}, qb.Values())
+	assert.Equal([]any{&d.id, &d.field1}, qb.ReturningValues())
+}
+
 func TestItCreatesASimpleInsertStatementForOracle(t *testing.T) {
 	qb := NewInsert("table1").
 		ForDatabase(ORACLE).
@@ -495,3 +534,2837 @@ func TestItReturnsAnErrorIfDatabaseEngineDoesNotSupportReturningClause(t *testin
 
 	assert.Equal(ErrDBEngineDoesNotSupportReturning, err)
 }
+
+func TestItCreatesASimpleSQLStatementForSQLServer(t *testing.T) {
+	var d struct {
+		field1 string
+		field2 int
+	}
+	qb := NewSelect("table1").
+		ForSQLServer().
+		Select("field1", "field2").
+		Into(&d.field1, &d.field2).
+		Where("table1.field1", "=", "value1").
+		Where("table1.field2", "IN", 1, 2).
+		OrderBy("table1.field1").
+		Limit(10, 20)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "SELECT table1.field1,table1.field2" +
+		" FROM table1" +
+		" WHERE table1.field1=@p1 AND table1.field2 IN (@p2,@p3)" +
+		" ORDER BY table1.field1 ASC" +
+		" OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", 1, 2}, qb.Criteria())
+}
+
+func TestItReturnsAnErrorIfSQLServerPaginatesWithoutOrderBy(t *testing.T) {
+	var field1 string
+	qry, err := NewSelect("table1").
+		ForSQLServer().
+		Select("field1").
+		Into(&field1).
+		Limit(10, 20).
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Equal(ErrOffsetFetchWithoutOrderBy, err)
+	assert.Equal("", qry)
+}
+
+func TestItReturnsAnErrorIfSQLServerUsesReturningClause(t *testing.T) {
+	var id int
+	qb := NewInsert("table1").
+		ForSQLServer().
+		Set("field1").
+		To("value1").
+		Returning("id").
+		Into(&id)
+
+	_, err := qb.GenerateQuery()
+	assert := assert.New(t)
+
+	assert.Equal(ErrDBEngineDoesNotSupportReturning, err)
+}
+
+func TestItCreatesAnSQLStatementWithWhereGroups(t *testing.T) {
+	var d struct {
+		field1 string
+		field2 int
+	}
+	qb := NewSelect("table1").
+		ForDatabase(POSTGRES).
+		Select("field1", "field2").
+		Into(&d.field1, &d.field2).
+		Where("table1.field1", "=", "value1").
+		WhereGroup(func(g *Builder) {
+			g.Where("table1.field2", "=", 2).
+				OrWhere("table1.field2", "BETWEEN", 10, 20)
+		}).
+		OrWhereGroup(func(g *Builder) {
+			g.Where("table1.field1", "LIKE", "value%").
+				WhereGroup(func(g *Builder) {
+					g.Where("table1.field2", "<", 5).
+						OrWhere("table1.field2", ">", 50)
+				})
+		})
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "SELECT table1.field1,table1.field2" +
+		" FROM table1" +
+		" WHERE table1.field1=$1" +
+		" AND (table1.field2=$2 OR table1.field2 BETWEEN $3 AND $4)" +
+		" OR (table1.field1 LIKE $5 AND (table1.field2<$6 OR table1.field2>$7))"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", 2, 10, 20, "value%", 5, 50}, qb.Criteria())
+}
+
+func TestItReturnsAnErrorIfAWhereGroupIsEmpty(t *testing.T) {
+	qb := NewDelete("table1").
+		Where("table1.field1", "=", "value1").
+		WhereGroup(func(g *Builder) {})
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrEmptyWhereGroup)
+	assert.Equal("", qry)
+}
+
+func TestItReturnsAnErrorIfTheFirstCriterionOfAWhereGroupIsAnOR(t *testing.T) {
+	qb := NewDelete("table1").
+		Where("table1.field1", "=", "value1").
+		WhereGroup(func(g *Builder) {
+			g.OrWhere("table1.field2", "=", 2)
+		})
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrFirstCriterionIsOr)
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAnSQLStatementWithNOTBETWEENoperator(t *testing.T) {
+	qb := NewDelete("table1").
+		ForDatabase(POSTGRES).
+		Where("table1.field1", "=", "value1").
+		Where("table1.field2", "not between", 1, 10)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "DELETE FROM table1 WHERE table1.field1=$1 AND table1.field2 NOT BETWEEN $2 AND $3"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", 1, 10}, qb.Criteria())
+}
+
+func TestItReturnsAnErrorIfNOTBETWEENDoesNotHaveTwoValues(t *testing.T) {
+	qb := NewDelete("table1").
+		Where("table1.field2", "NOT BETWEEN", 1)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrBadBetweenValues)
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAnSQLStatementWithINoperatorForPostgres(t *testing.T) {
+	qb := NewDelete("table1").
+		ForDatabase(POSTGRES).
+		Where("table1.field1", "=", "value1").
+		Where("table1.field2", "IN", 1, 2, 3)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "DELETE FROM table1 WHERE table1.field1=$1 AND table1.field2 IN ($2,$3,$4)"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+}
+
+func TestItReturnsAnErrorIfBETWEENDoesNotHaveTwoValues(t *testing.T) {
+	qb := NewDelete("table1").
+		Where("table1.field2", "BETWEEN", 1)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrBadBetweenValues)
+	assert.Equal("", qry)
+}
+
+func TestItReturnsAnErrorIfINHasNoValues(t *testing.T) {
+	qb := NewDelete("table1").
+		Where("table1.field2", "IN")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrEmptyInValues)
+	assert.Equal("", qry)
+}
+
+func TestItReturnsAnErrorIfINHasAnEmptySlice(t *testing.T) {
+	ids := []any{}
+	qb := NewDelete("table1").
+		Where("table1.field1", "=", "value1").
+		Where("table1.field2", "IN", ids...)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrEmptyInValues)
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAFalsePredicateIfINHasAnEmptySlice(t *testing.T) {
+	ids := []any{}
+	qb := NewDelete("table1").
+		ForDatabase(POSTGRES).
+		EmptyInAsFalse().
+		Where("table1.field2", "IN", ids...).
+		Where("table1.field1", "=", "value1")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "DELETE FROM table1 WHERE 1=0 AND table1.field1=$1"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1"}, qb.Criteria())
+}
+
+func TestItMapsTheNotEqualOperatorToTheStandardForm(t *testing.T) {
+	qb := NewDelete("table1").
+		Where("table1.field1", "!=", "value1").
+		OrWhere("table1.field2", "<>", 2)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "DELETE FROM table1 WHERE table1.field1<>? OR table1.field2<>?"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+}
+
+func TestItCreatesAnSQLStatementWithRawCriteria(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	qb := NewSelect("table1").
+		ForDatabase(POSTGRES).
+		Select("field1").
+		Into(&d.field1).
+		Where("table1.field2", "=", 2).
+		WhereRaw("lower(table1.field1)=lower(?)", "Value1").
+		OrWhereRaw("table1.field3 BETWEEN ? AND ? + 10", 5, 5)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "SELECT table1.field1" +
+		" FROM table1" +
+		" WHERE table1.field2=$1" +
+		" AND (lower(table1.field1)=lower($2))" +
+		" OR (table1.field3 BETWEEN $3 AND $4 + 10)"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{2, "Value1", 5, 5}, qb.Criteria())
+}
+
+func TestItWrapsRawCriteriaWithAnORInParentheses(t *testing.T) {
+	qry, err := NewDelete("table1").
+		Where("table1.field1", "=", 1).
+		WhereRaw("table1.field2=? OR table1.field3=?", 2, 3).
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("DELETE FROM table1 WHERE table1.field1=? AND (table1.field2=? OR table1.field3=?)", qry)
+
+	qry, err = NewUpdate("table1").
+		ForPostgres().
+		Set("field1").
+		To(1).
+		InnerJoin("table2", "table2.table1_id", "table1.id").
+		WhereRaw("table2.field2=? OR table2.field3=?", 2, 3).
+		GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("UPDATE table1 SET field1=$1 FROM table2"+
+		" WHERE table2.table1_id=table1.id AND (table2.field2=$2 OR table2.field3=$3)", qry)
+}
+
+func TestItReturnsAnErrorIfRawCriteriaPlaceholdersNotEqualToValues(t *testing.T) {
+	qb := NewDelete("table1").
+		WhereRaw("table1.field1=? OR table1.field2=?", 1)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, err.(ErrBadPlaceholdersValuesCombo))
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAnSQLStatementWithILIKEoperatorForPostgres(t *testing.T) {
+	qb := NewDelete("table1").
+		ForDatabase(POSTGRES).
+		WhereILike("table1.field1", "value%").
+		Where("table1.field2", "ilike", "%value")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "DELETE FROM table1 WHERE table1.field1 ILIKE $1 AND table1.field2 ILIKE $2"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value%", "%value"}, qb.Criteria())
+}
+
+func TestItReturnsAnErrorIfDatabaseEngineDoesNotSupportILIKE(t *testing.T) {
+	qb := NewDelete("table1").
+		ForDatabase(MYSQL).
+		WhereILike("table1.field1", "value%")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportILike)
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAnSQLStatementWithTypedJoins(t *testing.T) {
+	var d struct {
+		field1 string
+		field2 string
+	}
+	qb := NewSelect("table1").
+		Select("field1", "table2.field2").
+		Into(&d.field1, &d.field2).
+		InnerJoin("table2", "table2.table1_id", "table1.id").
+		LeftJoin("table3", "table3.table1_id", "table1.id").
+		RightJoin("table4", "table4.table1_id", "table1.id").
+		CrossJoin("table5").
+		Join("LEFT", "table6", "table6.table1_id", "table1.id")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "SELECT table1.field1,table2.field2" +
+		" FROM table1 INNER JOIN table2 ON table2.table1_id=table1.id" +
+		" LEFT JOIN table3 ON table3.table1_id=table1.id" +
+		" RIGHT JOIN table4 ON table4.table1_id=table1.id" +
+		" CROSS JOIN table5" +
+		" LEFT JOIN table6 ON table6.table1_id=table1.id"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+}
+
+func TestItCreatesAnSQLStatementWithUsingJoins(t *testing.T) {
+	var d struct {
+		field1 string
+		field2 string
+	}
+	qb := NewSelect("table1").
+		Select("field1", "table2.field2").
+		Into(&d.field1, &d.field2).
+		JoinUsing("LEFT", "table2", "id", "type").
+		Join("INNER", "table3", "table3.table1_id", "table1.id").
+		JoinUsing("INNER", "table4", "id")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "SELECT table1.field1,table2.field2" +
+		" FROM table1 LEFT JOIN table2 USING (id,type)" +
+		" INNER JOIN table3 ON table3.table1_id=table1.id" +
+		" INNER JOIN table4 USING (id)"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+}
+
+func TestItCreatesAnUpdateStatementWithRawAssignments(t *testing.T) {
+	qb := NewUpdate("table1").
+		ForDatabase(POSTGRES).
+		Set("field1").
+		To("value1").
+		SetRaw("views", "views+?", 1).
+		SetRaw("updated_at", "now()").
+		Set("field2").
+		To(2).
+		Where("table1.id", "=", 10)
+
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "UPDATE table1 SET field1=$1,views=views+$2,updated_at=now(),field2=$3" +
+		" WHERE table1.id=$4"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", 1, 2}, qb.Values())
+	assert.Equal([]any{10}, qb.Criteria())
+}
+
+func TestItReturnsAnErrorIfRawAssignmentPlaceholdersNotEqualToValues(t *testing.T) {
+	qb := NewUpdate("table1").
+		SetRaw("views", "views+?").
+		Where("table1.id", "=", 10)
+
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, err.(ErrBadPlaceholdersValuesCombo))
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAnUpdateStatementForPostgresWithReturningClause(t *testing.T) {
+	var d struct {
+		id     int
+		field1 string
+	}
+	qb := NewUpdate("table1").
+		ForDatabase(POSTGRES).
+		Set("field1", "field2").
+		To("value1", 2).
+		Where("table1.id", "=", 10).
+		Returning("id", "field1").
+		Into(&d.id, &d.field1)
+
+	qry, err := qb.GenerateQuery()
+	assert := assert.New(t)
+	expected := "UPDATE table1 SET field1=$1,field2=$2" +
+		" WHERE table1.id=$3" +
+		" RETURNING table1.id,table1.field1"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", 2}, qb.Values())
+	assert.Equal([]any{10}, qb.Criteria())
+	assert.Equal([]any{&d.id, &d.field1}, qb.ReturningValues())
+}
+
+func TestItCreatesADeleteStatementForPostgresWithReturningClause(t *testing.T) {
+	var id int
+	qb := NewDelete("table1").
+		ForDatabase(POSTGRES).
+		Where("table1.field1", "=", "value1").
+		Returning("id").
+		Into(&id)
+
+	qry, err := qb.GenerateQuery()
+	assert := assert.New(t)
+	expected := "DELETE FROM table1 WHERE table1.field1=$1 RETURNING table1.id"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Empty(qb.Values())
+	assert.Equal([]any{"value1"}, qb.Criteria())
+	assert.Equal([]any{&id}, qb.ReturningValues())
+}
+
+func TestItReturnsAnErrorIfUpdateReturningColumnsNotEqualToValues(t *testing.T) {
+	var id int
+	qb := NewUpdate("table1").
+		ForDatabase(POSTGRES).
+		Set("field1").
+		To("value1").
+		Returning("id", "field1").
+		Into(&id)
+
+	qry, err := qb.GenerateQuery()
+	assert := assert.New(t)
+	assert.ErrorIs(err, err.(ErrBadColumnsValuesCombo))
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAnSQLStatementWithEXISTSSubqueries(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	qb := NewSelect("table1").
+		ForDatabase(POSTGRES).
+		Select("field1").
+		Into(&d.field1).
+		Where("table1.field1", "=", "value1").
+		WhereExists(
+			NewSelect("table2").
+				Select("id").
+				WhereRaw("table2.table1_id=table1.id").
+				Where("table2.field2", "=", 2),
+		).
+		WhereNotExists(
+			NewSelect("table3").
+				Select("id").
+				Where("table3.field3", "IN", 3, 4),
+		).
+		Where("table1.field4", "=", 5)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "SELECT table1.field1" +
+		" FROM table1" +
+		" WHERE table1.field1=$1" +
+		" AND EXISTS (SELECT table2.id FROM table2 WHERE (table2.table1_id=table1.id) AND table2.field2=$2)" +
+		" AND NOT EXISTS (SELECT table3.id FROM table3 WHERE table3.field3 IN ($3,$4))" +
+		" AND table1.field4=$5"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", 2, 3, 4, 5}, qb.Criteria())
+}
+
+func TestItReturnsAnErrorIfAnEXISTSSubqueryIsNotASelect(t *testing.T) {
+	qb := NewDelete("table1").
+		WhereExists(NewDelete("table2").Where("table2.id", "=", 1))
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrNotSelectQuery)
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAnSQLStatementWithINSubquery(t *testing.T) {
+	qb := NewDelete("table1").
+		ForDatabase(ORACLE).
+		Where("table1.field1", "=", "value1").
+		WhereInSubquery(
+			"table1.id",
+			NewSelect("table2").
+				Select("table1_id").
+				Where("table2.field2", "BETWEEN", 1, 10),
+		).
+		Where("table1.field3", "=", 3)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "DELETE FROM table1" +
+		" WHERE table1.field1=:1" +
+		" AND table1.id IN (SELECT table2.table1_id FROM table2 WHERE table2.field2 BETWEEN :2 AND :3)" +
+		" AND table1.field3=:4"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", 1, 10, 3}, qb.Criteria())
+}
+
+func TestItReturnsAnErrorIfAnINSubqueryIsNotASelect(t *testing.T) {
+	qb := NewDelete("table1").
+		WhereInSubquery("table1.id", NewUpdate("table2").Set("field1").To(1))
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrNotSelectQuery)
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAnSQLStatementWithCommonTableExpressions(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	qb := NewSelect("table1").
+		ForDatabase(POSTGRES).
+		With(
+			"recent",
+			NewSelect("table2").
+				Select("table1_id").
+				Where("table2.created_at", ">", "2023-01-01"),
+		).
+		With(
+			"active",
+			NewSelect("table3").
+				Select("table1_id").
+				Where("table3.status", "IN", "a", "b"),
+		).
+		Select("field1").
+		Into(&d.field1).
+		Join("INNER", "recent", "recent.table1_id", "table1.id").
+		Join("INNER", "active", "active.table1_id", "table1.id").
+		Where("table1.field1", "=", "value1")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "WITH recent AS (SELECT table2.table1_id FROM table2 WHERE table2.created_at>$1)," +
+		"active AS (SELECT table3.table1_id FROM table3 WHERE table3.status IN ($2,$3))" +
+		" SELECT table1.field1" +
+		" FROM table1 INNER JOIN recent ON recent.table1_id=table1.id" +
+		" INNER JOIN active ON active.table1_id=table1.id" +
+		" WHERE table1.field1=$4"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"2023-01-01", "a", "b", "value1"}, qb.Criteria())
+}
+
+func TestItCreatesAnSQLStatementWithUnions(t *testing.T) {
+	var d struct {
+		id     int
+		field1 string
+	}
+	qb := NewSelect("table1").
+		ForDatabase(POSTGRES).
+		Select("id", "field1").
+		Into(&d.id, &d.field1).
+		Where("table1.field2", "=", 1).
+		Union(
+			NewSelect("table2").
+				Select("id", "field1").
+				Where("table2.field2", "=", 2),
+		).
+		UnionAll(
+			NewSelect("table3").
+				Select("id", "field1").
+				Where("table3.field2", "IN", 3, 4),
+		).
+		OrderBy("id").
+		Limit(10, 0)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "SELECT table1.id,table1.field1 FROM table1 WHERE table1.field2=$1" +
+		" UNION SELECT table2.id,table2.field1 FROM table2 WHERE table2.field2=$2" +
+		" UNION ALL SELECT table3.id,table3.field1 FROM table3 WHERE table3.field2 IN ($3,$4)" +
+		" ORDER BY id ASC" +
+		" LIMIT 10"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{1, 2, 3, 4}, qb.Criteria())
+}
+
+func TestItReturnsAnErrorIfUnionColumnsCountsDiffer(t *testing.T) {
+	var d struct {
+		id     int
+		field1 string
+	}
+	qb := NewSelect("table1").
+		Select("id", "field1").
+		Into(&d.id, &d.field1).
+		Union(NewSelect("table2").Select("id"))
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, err.(ErrUnionColumnsMismatch))
+	assert.Equal("", qry)
+}
+
+func TestItCreatesACountQueryFromASelect(t *testing.T) {
+	var d struct {
+		field1 string
+		field2 string
+	}
+	qb := NewSelect("table1").
+		ForDatabase(POSTGRES).
+		Select("field1", "table2.field2").
+		Into(&d.field1, &d.field2).
+		Join("LEFT", "table2", "table2.table1_id", "table1.id").
+		Where("table1.field1", "=", "value1").
+		Where("table2.field2", "IN", 1, 2).
+		OrderBy("table1.field1").
+		Limit(10, 20)
+	cq, err := qb.CountQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	var total int
+	qry, err := cq.Into(&total).GenerateQuery()
+	expected := "SELECT COUNT(*)" +
+		" FROM table1 LEFT JOIN table2 ON table2.table1_id=table1.id" +
+		" WHERE table1.field1=$1 AND table2.field2 IN ($2,$3)"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal(qb.Criteria(), cq.Criteria())
+	assert.Equal([]any{&total}, cq.Values())
+}
+
+func TestItCreatesACountQueryFromADistinctSelect(t *testing.T) {
+	var field1 string
+	qb := NewSelect("table1").
+		ForPostgres().
+		Distinct().
+		Select("field1").
+		Into(&field1).
+		Where("table1.field2", "=", "value2").
+		OrderBy("field1").
+		Limit(10, 0)
+	cq, err := qb.CountQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	var total int
+	qry, err := cq.Into(&total).GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT COUNT(*) FROM (SELECT DISTINCT table1.field1 FROM table1 WHERE table1.field2=$1) sub", qry)
+	assert.Equal([]any{"value2"}, cq.Criteria())
+
+	cq, err = NewSelect("table1").
+		ForPostgres().
+		DistinctOn("field1").
+		Select("field1", "field2").
+		OrderBy("field1").
+		CountQuery()
+	assert.Nil(err)
+	qry, err = cq.GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT COUNT(*) FROM (SELECT DISTINCT ON (table1.field1) table1.field1,table1.field2 FROM table1) sub", qry)
+}
+
+func TestItKeepsACountQueryWhenTheBuilderIsReused(t *testing.T) {
+	expected := map[bool]string{
+		false: "SELECT COUNT(*) FROM table1 INNER JOIN table2 ON table2.table1_id=table1.id WHERE table1.field2=$1",
+		true: "SELECT COUNT(*) FROM (SELECT DISTINCT table1.field1 FROM table1" +
+			" INNER JOIN table2 ON table2.table1_id=table1.id WHERE table1.field2=$1) sub",
+	}
+	assert := assert.New(t)
+	for _, distinct := range []bool{false, true} {
+		qb := NewSelect("table1").
+			ForPostgres().
+			Select("field1").
+			Join("INNER", "table2", "table2.table1_id", "table1.id").
+			Where("table1.field2", "=", "value2")
+		if distinct {
+			qb.Distinct()
+		}
+		cq, err := qb.CountQuery()
+		assert.Nil(err)
+
+		qb.Reset().
+			Select("field3").
+			Join("LEFT", "table3", "table3.table1_id", "table1.id").
+			Where("table1.field4", "=", "value4")
+		qry, err := cq.GenerateQuery()
+		assert.Nil(err)
+		assert.Equal(expected[distinct], qry)
+		assert.Equal([]any{"value2"}, cq.Criteria())
+	}
+}
+
+func TestItKeepsTheIndexHintsAndLegacyJoinsInACountQuery(t *testing.T) {
+	cq, err := NewSelect("table1").
+		ForMySQL().
+		UseIndex("idx_field1").
+		Select("field1").
+		Where("table1.field1", "=", 1).
+		CountQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	qry, err := cq.GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT COUNT(*) FROM table1 USE INDEX (idx_field1) WHERE table1.field1=?", qry)
+
+	cq, err = NewSelect("table1").
+		ForOracle().
+		LegacyOracleJoins().
+		Select("field1").
+		LeftJoin("table2", "table2.table1_id", "table1.id").
+		CountQuery()
+	assert.Nil(err)
+	qry, err = cq.GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT COUNT(*) FROM table1,table2 WHERE table2.table1_id(+)=table1.id", qry)
+}
+
+func TestItReturnsAnErrorIfCountQueryIsNotASelect(t *testing.T) {
+	_, err := NewDelete("table1").Where("table1.id", "=", 1).CountQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrNotSelectQuery)
+}
+
+func TestItRecordsTheFirstErrorWhileDefiningTheQuery(t *testing.T) {
+	qb := NewDelete("table1").
+		Where("table1.field1", "=", "value1")
+
+	assert := assert.New(t)
+	assert.Nil(qb.Err())
+
+	qb.Where("table1.field2", "ins", 1, 2).
+		Where("table1.field3", "BETWEEN", 1).
+		WhereRaw("table1.field4=?")
+	assert.ErrorIs(qb.Err(), NewInvalidOperatorError("INS"))
+
+	qry, err := qb.GenerateQuery()
+	assert.ErrorIs(err, NewInvalidOperatorError("INS"))
+	assert.Equal("", qry)
+}
+
+func TestItRecordsErrorsOfWhereGroups(t *testing.T) {
+	qb := NewDelete("table1").
+		Where("table1.field1", "=", "value1").
+		WhereGroup(func(g *Builder) {
+			g.Where("table1.field2", "BETWEEN", 1)
+		})
+
+	assert := assert.New(t)
+	assert.ErrorIs(qb.Err(), ErrBadBetweenValues)
+}
+
+func TestItMergesTheCriteriaOfAnotherBuilder(t *testing.T) {
+	filters := NewSelect("table1").
+		OrWhere("table1.field2", "=", 2).
+		OrWhere("table1.field3", "IN", 3, 4)
+	qb := NewDelete("table1").
+		Where("table1.field1", "=", "value1").
+		MergeWhere(filters)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("DELETE FROM table1 WHERE table1.field1=? AND (table1.field2=? OR table1.field3 IN (?,?))", qry)
+	assert.Equal([]interface{}{"value1", 2, 3, 4}, qb.Criteria())
+
+	qb = NewDelete("table1").MergeWhere(NewSelect("table1").Where("table1.field1", "BETWEEN", 1))
+	assert.ErrorIs(qb.Err(), ErrBadBetweenValues)
+}
+
+func TestItMergesColumnComparisonsOfAnotherTable(t *testing.T) {
+	filters := NewSelect("table2").
+		WhereColumn("field1", "=", "field2").
+		WhereGroup(func(g *Builder) {
+			g.WhereColumn("field3", "<", "table1.field3")
+		})
+	qb := NewDelete("table1").
+		Where("table1.field1", "=", "value1").
+		MergeWhere(filters)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("DELETE FROM table1 WHERE table1.field1=? AND (table2.field1=table2.field2 AND (table2.field3<table1.field3))", qry)
+}
+
+func TestItReturnsAnErrorIfTheTableNameIsEmpty(t *testing.T) {
+	var id int
+	builders := map[string]*Builder{
+		"select": NewSelect("").Select("id").Into(&id),
+		"insert": NewInsert("").Set("field1").To("value1"),
+		"update": NewUpdate("").Set("field1").To("value1"),
+		"delete": NewDelete("").Where("id", "=", 1),
+	}
+
+	assert := assert.New(t)
+	for name, qb := range builders {
+		qry, err := qb.GenerateQuery()
+		assert.ErrorIs(err, ErrEmptyTableName, name)
+		assert.Equal("", qry, name)
+	}
+}
+
+func TestItCreatesAnSQLStatementWithConditionalCriteria(t *testing.T) {
+	name, status := "value1", ""
+	qb := NewDelete("table1").
+		WhereIf(name != "", "table1.name", "=", name).
+		WhereIf(status != "", "table1.status", "=", status).
+		OrWhereIf(true, "table1.field1", "IN", 1, 2).
+		OrWhereIf(false, "table1.field2", "=", 3)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "DELETE FROM table1 WHERE table1.name=? OR table1.field1 IN (?,?)"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", 1, 2}, qb.Criteria())
+}
+
+func TestItCreatesAnSQLStatementWithOrderDirectionAndRawOrder(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	sortDesc := true
+	qb := NewSelect("table1").
+		ForDatabase(SQLITE).
+		Select("field1").
+		Into(&d.field1).
+		OrderByDirection("table1.field1", sortDesc).
+		OrderByDirection("table1.field2", !sortDesc).
+		OrderByRaw("RANDOM()")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "SELECT table1.field1" +
+		" FROM table1" +
+		" ORDER BY table1.field1 DESC,table1.field2 ASC,RANDOM()"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+}
+
+func TestItCreatesAnSQLStatementWithNullsOrder(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	qb := NewSelect("table1").
+		ForDatabase(POSTGRES).
+		Select("field1").
+		Into(&d.field1).
+		OrderByNullsFirst("table1.field1").
+		OrderByNullsLast("table1.field2").
+		OrderByDescending("table1.field3").
+		OrderByDescendingNullsFirst("table1.field4").
+		OrderByDescendingNullsLast("table1.field5")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "SELECT table1.field1" +
+		" FROM table1" +
+		" ORDER BY table1.field1 ASC NULLS FIRST,table1.field2 ASC NULLS LAST,table1.field3 DESC," +
+		"table1.field4 DESC NULLS FIRST,table1.field5 DESC NULLS LAST"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+}
+
+func TestItReturnsAnErrorIfDatabaseEngineDoesNotSupportNullsOrder(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	qb := NewSelect("table1").
+		ForDatabase(MYSQL).
+		Select("field1").
+		Into(&d.field1).
+		OrderByNullsLast("table1.field1")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportNullsOrder)
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAnSQLStatementWithSchemaQualifiedTables(t *testing.T) {
+	var d struct {
+		field1 string
+		field2 string
+		field3 string
+	}
+	qb := NewSelect("analytics.events").
+		ForDatabase(POSTGRES).
+		Select("field1", "analytics.sessions.field2", "events.field3").
+		Into(&d.field1, &d.field2, &d.field3).
+		Join("LEFT", "analytics.sessions", "analytics.sessions.id", "analytics.events.session_id").
+		Where("analytics.events.field1", "=", "value1")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "SELECT analytics.events.field1,analytics.sessions.field2,events.field3" +
+		" FROM analytics.events" +
+		" LEFT JOIN analytics.sessions ON analytics.sessions.id=analytics.events.session_id" +
+		" WHERE analytics.events.field1=$1"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+}
+
+func TestItCreatesAnInsertStatementWithSchemaQualifiedReturningColumns(t *testing.T) {
+	var d struct {
+		id     int
+		field1 string
+	}
+	qb := NewInsert("analytics.events").
+		ForDatabase(POSTGRES).
+		Set("field1").
+		To("value1").
+		Returning("id", "analytics.events.field1").
+		Into(&d.id, &d.field1)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "INSERT INTO analytics.events (field1) VALUES ($1)" +
+		" RETURNING analytics.events.id,analytics.events.field1"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+}
+
+func TestItBuildsASelectStatementWithItsArguments(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	qb := NewSelect("table1").
+		ForDatabase(POSTGRES).
+		Select("field1").
+		Into(&d.field1).
+		Where("table1.field1", "=", "value1").
+		Where("table1.field2", "IN", 1, 2)
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	expected := "SELECT table1.field1 FROM table1 WHERE table1.field1=$1 AND table1.field2 IN ($2,$3)"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", 1, 2}, args)
+}
+
+func TestItBuildsAnUpdateStatementWithItsArguments(t *testing.T) {
+	qb := NewUpdate("table1").
+		ForDatabase(POSTGRES).
+		With("ids", NewSelect("table2").Select("table1_id").Where("table2.field1", "=", "value1")).
+		Set("field1").
+		To("value2").
+		SetRaw("views", "views+?", 1).
+		WhereInSubquery("table1.id", NewSelect("ids").Select("table1_id")).
+		Where("table1.field2", "=", 3)
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	expected := "WITH ids AS (SELECT table2.table1_id FROM table2 WHERE table2.field1=$1)" +
+		" UPDATE table1 SET field1=$2,views=views+$3" +
+		" WHERE table1.id IN (SELECT ids.table1_id FROM ids) AND table1.field2=$4"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", "value2", 1, 3}, args)
+}
+
+func TestItBuildsAnInsertStatementWithItsArguments(t *testing.T) {
+	qb := NewInsert("table1").
+		Set("field1", "field2").
+		To("value1", 2)
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("INSERT INTO table1 (field1,field2) VALUES (?,?)", qry)
+	assert.Equal([]any{"value1", 2}, args)
+}
+
+func TestItReturnsAnErrorIfTheBuildFails(t *testing.T) {
+	qry, args, err := NewDelete("table1").Where("table1.field1", "==", 1).Build()
+
+	assert := assert.New(t)
+	assert.NotNil(err)
+	assert.Equal("", qry)
+	assert.Nil(args)
+}
+
+func TestItReturnsTheArgumentsOfAnUpdateInPlaceholderOrder(t *testing.T) {
+	qb := NewUpdate("table1").
+		ForDatabase(POSTGRES).
+		Where("table1.id", "=", 10).
+		Set("field1", "field2").
+		To("value1", 2).
+		Where("table1.field3", "BETWEEN", 1, 5)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "UPDATE table1 SET field1=$1,field2=$2 WHERE table1.id=$3 AND table1.field3 BETWEEN $4 AND $5"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", 2, 10, 1, 5}, qb.Args())
+}
+
+func TestItReturnsOnlyTheCriteriaAsArgumentsOfASelect(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	qb := NewSelect("table1").
+		Select("field1").
+		Into(&d.field1).
+		Where("table1.field1", "=", "value1")
+
+	assert := assert.New(t)
+	assert.Equal([]any{"value1"}, qb.Args())
+	assert.Equal([]any{&d.field1}, qb.Values())
+}
+
+func TestItCreatesATruncateStatement(t *testing.T) {
+	assert := assert.New(t)
+
+	qry, err := NewTruncate("table1").ForPostgres().GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("TRUNCATE TABLE table1", qry)
+
+	qry, err = NewTruncate("table1").ForSQLite().GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("DELETE FROM table1", qry)
+}
+
+func TestItReturnsAnErrorIfATruncateStatementHasClauses(t *testing.T) {
+	builders := []*Builder{
+		NewTruncate("table1").Where("table1.id", "=", 1),
+		NewTruncate("table1").Join("LEFT", "table2", "table2.table1_id", "table1.id"),
+		NewTruncate("table1").OrderBy("table1.id"),
+	}
+
+	assert := assert.New(t)
+	for _, qb := range builders {
+		qry, err := qb.GenerateQuery()
+		assert.ErrorIs(err, ErrTruncateWithClauses)
+		assert.Equal("", qry)
+	}
+}
+
+func TestItCreatesADeleteStatementWithJoinsForMySQL(t *testing.T) {
+	qb := NewDelete("table1").
+		ForMySQL().
+		DeleteFrom("table1", "table2").
+		Join("INNER", "table2", "table2.table1_id", "table1.id").
+		Where("table2.field1", "=", "value1")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "DELETE table1,table2 FROM table1" +
+		" INNER JOIN table2 ON table2.table1_id=table1.id" +
+		" WHERE table2.field1=?"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1"}, qb.Criteria())
+}
+
+func TestItReturnsAnErrorIfDatabaseEngineDoesNotSupportMultiTableDelete(t *testing.T) {
+	qb := NewDelete("table1").
+		ForPostgres().
+		DeleteFrom("table1").
+		Join("INNER", "table2", "table2.table1_id", "table1.id")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportMultiTableDelete)
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAnUpdateStatementWithJoinsForPostgres(t *testing.T) {
+	qb := NewUpdate("table1").
+		ForPostgres().
+		Set("field1").
+		To("value1").
+		Join("INNER", "table2", "table2.table1_id", "table1.id").
+		CrossJoin("table3").
+		Where("table2.field2", "=", 2).
+		OrWhere("table3.field3", "=", 3)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "UPDATE table1 SET field1=$1" +
+		" FROM table2,table3" +
+		" WHERE table2.table1_id=table1.id AND (table2.field2=$2 OR table3.field3=$3)"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", 2, 3}, qb.Args())
+}
+
+func TestItCreatesAnUpdateStatementWithJoinsForMySQL(t *testing.T) {
+	qb := NewUpdate("table1").
+		ForMySQL().
+		Set("table1.field1").
+		To("value1").
+		Join("LEFT", "table2", "table2.table1_id", "table1.id").
+		Where("table2.field2", "=", 2)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "UPDATE table1 LEFT JOIN table2 ON table2.table1_id=table1.id" +
+		" SET table1.field1=?" +
+		" WHERE table2.field2=?"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+}
+
+func TestItReturnsAnErrorIfAnUpdateFromHasAnOuterJoin(t *testing.T) {
+	qb := NewUpdate("table1").
+		ForPostgres().
+		Set("field1").
+		To("value1").
+		Join("LEFT", "table2", "table2.table1_id", "table1.id")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrUpdateFromRequiresInnerJoin)
+	assert.Equal("", qry)
+}
+
+func TestItReturnsAnErrorIfDatabaseEngineDoesNotSupportUpdateJoins(t *testing.T) {
+	qb := NewUpdate("table1").
+		ForOracle().
+		Set("field1").
+		To("value1").
+		Join("INNER", "table2", "table2.table1_id", "table1.id")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportUpdateJoin)
+	assert.Equal("", qry)
+}
+
+func TestItGeneratesTheSameQueryOnConsecutiveCalls(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	qb := NewSelect("table1").
+		ForPostgres().
+		Select("field1").
+		Into(&d.field1).
+		Where("table1.field1", "=", "value1").
+		WhereExists(NewSelect("table2").Select("id").Where("table2.field2", "=", 2))
+
+	assert := assert.New(t)
+	first, err := qb.GenerateQuery()
+	assert.Nil(err)
+	second, err := qb.GenerateQuery()
+	assert.Nil(err)
+	assert.Equal(first, second)
+	assert.Equal("SELECT table1.field1 FROM table1"+
+		" WHERE table1.field1=$1 AND EXISTS (SELECT table2.id FROM table2 WHERE table2.field2=$2)", second)
+}
+
+func TestItEscapesLikeWildcards(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(`100\%\_off\\`, EscapeLike(`100%_off\`))
+	assert.Equal("plain", EscapeLike("plain"))
+}
+
+func TestItCreatesAnSQLStatementWithEscapedLIKE(t *testing.T) {
+	assert := assert.New(t)
+
+	qb := NewDelete("table1").
+		ForSQLite().
+		WhereLike("table1.field1", "%"+EscapeLike("50%")+"%")
+	qry, err := qb.GenerateQuery()
+	assert.Nil(err)
+	assert.Equal(`DELETE FROM table1 WHERE table1.field1 LIKE ? ESCAPE '\'`, qry)
+	assert.Equal([]any{`%50\%%`}, qb.Criteria())
+
+	qb = NewDelete("table1").
+		ForMySQL().
+		WhereLike("table1.field1", "%"+EscapeLike("50%")+"%")
+	qry, err = qb.GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("DELETE FROM table1 WHERE table1.field1 LIKE ?", qry)
+}
+
+func TestItCreatesAnSQLStatementWithANYandALL(t *testing.T) {
+	ids := []int64{1, 2, 3}
+	prices := []float64{10, 20}
+	qb := NewDelete("table1").
+		ForPostgres().
+		Where("table1.field1", "=", "value1").
+		WhereAny("table1.id", "=", ids).
+		WhereAll("table1.price", ">", prices)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "DELETE FROM table1 WHERE table1.field1=$1 AND table1.id=ANY($2) AND table1.price>ALL($3)"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", ids, prices}, qb.Criteria())
+}
+
+func TestItReturnsAnErrorIfANYHasAnInvalidOperator(t *testing.T) {
+	qb := NewDelete("table1").
+		ForPostgres().
+		WhereAny("table1.id", "IN", []int64{1, 2})
+
+	assert := assert.New(t)
+	assert.ErrorIs(qb.Err(), NewInvalidOperatorError("IN"))
+}
+
+func TestItReturnsAnErrorIfDatabaseEngineDoesNotSupportANY(t *testing.T) {
+	qb := NewDelete("table1").
+		ForMySQL().
+		WhereAny("table1.id", "=", []int64{1, 2})
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportAnyAll)
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAnSQLStatementWithCOALESCEandNULLIF(t *testing.T) {
+	var d struct {
+		id    int
+		name  string
+		email string
+	}
+	qb := NewSelect("table1").
+		Select("id").
+		SelectCoalesce("name", "nickname", "table2.name", "first_name").
+		SelectNullIf("email", "email", "table2.email").
+		Into(&d.id, &d.name, &d.email).
+		Join("LEFT", "table2", "table2.table1_id", "table1.id")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	expected := "SELECT table1.id," +
+		"COALESCE(table1.nickname,table2.name,table1.first_name) AS name," +
+		"NULLIF(table1.email,table2.email) AS email" +
+		" FROM table1 LEFT JOIN table2 ON table2.table1_id=table1.id"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{&d.id, &d.name, &d.email}, qb.Values())
+}
+
+func TestItCreatesAnSQLStatementWithACOALESCEFallback(t *testing.T) {
+	var d struct {
+		amount float64
+		name   string
+	}
+	qb := NewSelect("table1").
+		ForPostgres().
+		SelectCoalesce("amount", "amount", "0").
+		SelectCoalesceDefault("name", "n/a", "nickname", "first_name").
+		Into(&d.amount, &d.name).
+		AllowColumns("amount", "nickname", "first_name", "active").
+		Where("table1.active", "=", true)
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT COALESCE(table1.amount,0) AS amount,"+
+		"COALESCE(table1.nickname,table1.first_name,$1) AS name"+
+		" FROM table1 WHERE table1.active=$2", qry)
+	assert.Equal([]any{"n/a", true}, args)
+}
+
+func TestItCreatesAnSQLStatementWithRowLocks(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	assert := assert.New(t)
+
+	qry, err := NewSelect("table1").
+		ForPostgres().
+		Select("field1").
+		Into(&d.field1).
+		Where("table1.id", "=", 1).
+		Limit(1, 0).
+		ForUpdate().
+		SkipLocked().
+		GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 WHERE table1.id=$1 LIMIT 1 FOR UPDATE SKIP LOCKED", qry)
+
+	qry, err = NewSelect("table1").
+		ForMySQL().
+		Select("field1").
+		Into(&d.field1).
+		ForShare().
+		NoWait().
+		GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 FOR SHARE NOWAIT", qry)
+}
+
+func TestItReturnsAnErrorIfDatabaseEngineDoesNotSupportTheRowLock(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	builders := []*Builder{
+		NewSelect("table1").ForSQLServer().Select("field1").Into(&d.field1).ForUpdate(),
+		NewSelect("table1").ForSQLite().Select("field1").Into(&d.field1).ForUpdate(),
+		NewSelect("table1").ForOracle().Select("field1").Into(&d.field1).ForShare(),
+	}
+
+	assert := assert.New(t)
+	for _, qb := range builders {
+		qry, err := qb.GenerateQuery()
+		assert.ErrorIs(err, ErrDBEngineDoesNotSupportRowLock)
+		assert.Equal("", qry)
+	}
+}
+
+func TestItCreatesAnSQLStatementWithPagination(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	assert := assert.New(t)
+
+	qb := NewSelect("table1").
+		ForMySQL().
+		Select("field1").
+		Into(&d.field1).
+		Page(1, 20)
+	qry, err := qb.GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 LIMIT 20", qry)
+
+	qry, err = qb.Page(0, 20).GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 LIMIT 20", qry)
+
+	qry, err = qb.Page(3, 20).GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 LIMIT 20 OFFSET 40", qry)
+
+	qry, err = qb.ForPostgres().GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 LIMIT 20 OFFSET 40", qry)
+
+	qry, err = qb.ForOracle().GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 OFFSET 40 ROWS FETCH NEXT 20 ROWS ONLY", qry)
+
+	qry, err = qb.Page(1, 20).GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 FETCH NEXT 20 ROWS ONLY", qry)
+}
+
+func TestItCreatesAnSQLStatementWithMultipleOrderByColumns(t *testing.T) {
+	var d struct {
+		field1 string
+	}
+	qry, err := NewSelect("table1").
+		Select("field1").
+		Into(&d.field1).
+		OrderByColumns("table1.field1", "table1.field2 DESC", "table1.field3 asc").
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 ORDER BY table1.field1 ASC,table1.field2 DESC,table1.field3 ASC", qry)
+}
+
+func TestItCreatesAnSQLStatementSelectingAllColumns(t *testing.T) {
+	qb := NewSelect("table1").
+		SelectAll().
+		Where("table1.field1", "=", "value1")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT * FROM table1 WHERE table1.field1=?", qry)
+	assert.Equal([]any{"value1"}, qb.Criteria())
+}
+
+func TestItCreatesAnSQLStatementWithAllowedColumns(t *testing.T) {
+	var d struct {
+		field1 string
+		field2 string
+	}
+	qry, err := NewSelect("table1").
+		AllowColumns("field1", "table1.field2", "table1.field3").
+		Select("field1").
+		SelectCoalesce("f2", "field2", "field3").
+		Into(&d.field1, &d.field2).
+		Where("field1", "=", "value1").
+		WhereGroup(func(gb *Builder) {
+			gb.Where("table1.field3", ">", 1).OrWhereRaw("field4 IS NULL")
+		}).
+		OrderByColumns("field2 DESC").
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1,COALESCE(table1.field2,table1.field3) AS f2 FROM table1"+
+		" WHERE field1=? AND (table1.field3>? OR (field4 IS NULL)) ORDER BY table1.field2 DESC", qry)
+}
+
+func TestItReturnsAnErrorIfAColumnIsNotAllowed(t *testing.T) {
+	var field1 string
+	builders := []*Builder{
+		NewSelect("table1").AllowColumns("field1").Select("field2").Into(&field1),
+		NewSelect("table1").AllowColumns("field1").Select("field1").Into(&field1).
+			Where("field2", "=", 1),
+		NewSelect("table1").AllowColumns("field1").Select("field1").Into(&field1).
+			WhereGroup(func(gb *Builder) { gb.Where("field2", "=", 1) }),
+		NewSelect("table1").AllowColumns("field1").Select("field1").Into(&field1).
+			OrderBy("field2"),
+		NewSelect("table1").AllowColumns("field1").SelectCoalesce("f", "field1", "field2").Into(&field1),
+		NewUpdate("table1").AllowColumns("field1").Set("field2").To(1),
+	}
+
+	assert := assert.New(t)
+	for _, qb := range builders {
+		qry, err := qb.GenerateQuery()
+		assert.IsType(ErrDisallowedColumn{}, err)
+		assert.Equal("", qry)
+	}
+	_, err := builders[3].GenerateQuery()
+	assert.EqualError(err, "column 'field2' is not allowed")
+}
+
+func TestItCreatesAnSQLStatementWithFunctionIdentifiersAndRawExpressions(t *testing.T) {
+	var field1 string
+	qry, err := NewSelect("schema1.table1").
+		Select("field1").
+		Into(&field1).
+		Where("LOWER(schema1.table1.field1)", "=", "value1").
+		WhereRaw("schema1.table1.field2 + 1 > ?", 2).
+		OrderByRaw("schema1.table1.field2 % 10").
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT schema1.table1.field1 FROM schema1.table1"+
+		" WHERE LOWER(schema1.table1.field1)=? AND (schema1.table1.field2 + 1 > ?)"+
+		" ORDER BY schema1.table1.field2 % 10", qry)
+}
+
+func TestItReturnsAnErrorIfAnIdentifierIsInvalid(t *testing.T) {
+	var field1 string
+	builders := []*Builder{
+		NewSelect("table1; DROP TABLE table2").Select("field1").Into(&field1),
+		NewSelect("table1").Select("field1 FROM table2 --").Into(&field1),
+		NewSelect("table1").Select("field1").Into(&field1).Where("field1=1 OR 1", "=", 1),
+		NewSelect("table1").Select("field1").Into(&field1).OrderBy("field1; DROP TABLE table1"),
+		NewSelect("table1").Select("field1").Into(&field1).Join("INNER", "table2 t2", "t2.id", "table1.id"),
+		NewSelect("table1").SelectCoalesce("f1 FROM table2", "field1", "field2").Into(&field1),
+		NewUpdate("table1").Set("field1='x',field2").To(1),
+	}
+
+	assert := assert.New(t)
+	for _, qb := range builders {
+		qry, err := qb.GenerateQuery()
+		assert.IsType(ErrInvalidIdentifier{}, err)
+		assert.Equal("", qry)
+	}
+	_, err := builders[0].GenerateQuery()
+	assert.EqualError(err, "identifier 'table1; DROP TABLE table2' is not a valid SQL identifier")
+}
+
+func TestItCreatesAnSQLStatementWithAliasesAndQuotedIdentifiers(t *testing.T) {
+	var d struct {
+		id    int
+		order int
+		count int
+	}
+	qry, err := NewSelect("table1").
+		ForPostgres().
+		Select("id AS user_id", `"order"`).
+		SelectRaw("COUNT(DISTINCT table1.field1) AS c").
+		Into(&d.id, &d.order, &d.count).
+		AllowColumns("id", `"order"`).
+		GroupBy("id", `"order"`).
+		OrderBy("user_id").
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal(`SELECT table1.id AS user_id,table1."order",COUNT(DISTINCT table1.field1) AS c`+
+		` FROM table1 GROUP BY table1.id,table1."order" ORDER BY user_id ASC`, qry)
+
+	_, err = NewSelect("table1").Select("id AS user_id; DROP TABLE table1").Into(&d.id).GenerateQuery()
+	assert.IsType(ErrInvalidIdentifier{}, err)
+
+	_, err = NewSelect("table1").Select(`"order"; DROP TABLE table1`).Into(&d.id).GenerateQuery()
+	assert.IsType(ErrInvalidIdentifier{}, err)
+}
+
+func TestItCreatesAnSQLStatementWithTheTableSetByFrom(t *testing.T) {
+	var field1 string
+	qb := Acquire().
+		From("table1").
+		Select("field1").
+		Into(&field1).
+		Where("table1.field1", "=", "value1")
+	defer Release(qb)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 WHERE table1.field1=?", qry)
+
+	qry, err = qb.From("table2").GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table2 WHERE table1.field1=?", qry)
+}
+
+func TestItCreatesASelfJoinWithTableAliases(t *testing.T) {
+	var d struct {
+		name    string
+		manager string
+	}
+	qb := NewSelect("users").
+		As("u").
+		Select("name", "m.name").
+		Into(&d.name, &d.manager).
+		JoinAs("LEFT", "users", "m", "m.id", "u.manager_id").
+		Where("u.active", "=", true)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT u.name,m.name FROM users u LEFT JOIN users m ON m.id=u.manager_id"+
+		" WHERE u.active=?", qry)
+	assert.Equal([]any{true}, qb.Criteria())
+}
+
+func TestItCreatesAnUpdateStatementWithATableAlias(t *testing.T) {
+	qry, err := NewUpdate("users").
+		ForPostgres().
+		As("u").
+		Set("name").
+		To("value1").
+		Where("u.id", "=", 1).
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("UPDATE users u SET name=$1 WHERE u.id=$2", qry)
+}
+
+func TestItCreatesAnSQLStatementWithAStringAggregation(t *testing.T) {
+	var names string
+	tests := []struct {
+		db       database
+		expected string
+	}{
+		{MYSQL, "SELECT GROUP_CONCAT(users.name SEPARATOR ', ') AS names FROM users"},
+		{SQLITE, "SELECT GROUP_CONCAT(users.name,', ') AS names FROM users"},
+		{POSTGRES, "SELECT STRING_AGG(users.name,', ') AS names FROM users"},
+		{SQLSERVER, "SELECT STRING_AGG(users.name,', ') AS names FROM users"},
+		{ORACLE, "SELECT LISTAGG(users.name,', ') AS names FROM users"},
+	}
+
+	assert := assert.New(t)
+	for _, test := range tests {
+		qry, err := NewSelect("users").
+			ForDatabase(test.db).
+			SelectStringAgg("name", ", ", "names").
+			Into(&names).
+			GenerateQuery()
+		assert.Nil(err)
+		assert.Equal(test.expected, qry)
+	}
+
+	qry, err := NewSelect("users").
+		ForMySQL().
+		SelectStringAgg("name", `\'`, "names").
+		Into(&names).
+		GenerateQuery()
+	assert.Nil(err)
+	assert.Equal(`SELECT GROUP_CONCAT(users.name SEPARATOR '\\''') AS names FROM users`, qry)
+}
+
+func TestItGeneratesAStringAggregationForTheFinalDatabaseEngine(t *testing.T) {
+	var names string
+	qb := NewSelect("users").
+		SelectStringAgg("name", ", ", "names").
+		Into(&names).
+		ForPostgres()
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT STRING_AGG(users.name,', ') AS names FROM users", qry)
+	assert.Equal([]string{"STRING_AGG(users.name,', ') AS names"}, qb.Columns())
+
+	qry, err = qb.ForOracle().GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT LISTAGG(users.name,', ') AS names FROM users", qry)
+
+	data, err := qb.MarshalJSON()
+	assert.Nil(err)
+	restored := &Builder{}
+	assert.Nil(restored.UnmarshalJSON(data))
+	qry, err = restored.ForMySQL().GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT GROUP_CONCAT(users.name SEPARATOR ', ') AS names FROM users", qry)
+}
+
+func TestItCreatesAnSQLStatementWithDistinct(t *testing.T) {
+	var field1 string
+	qry, err := NewSelect("table1").
+		Distinct().
+		Select("field1").
+		Into(&field1).
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT DISTINCT table1.field1 FROM table1", qry)
+}
+
+func TestItCreatesAnSQLStatementForPostgresWithDistinctOn(t *testing.T) {
+	var d struct {
+		userID    int
+		createdAt string
+	}
+	qry, err := NewSelect("orders").
+		ForPostgres().
+		DistinctOn("user_id").
+		Select("user_id", "created_at").
+		Into(&d.userID, &d.createdAt).
+		OrderBy("orders.user_id").
+		OrderByDescending("orders.created_at").
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT DISTINCT ON (orders.user_id) orders.user_id,orders.created_at FROM orders"+
+		" ORDER BY orders.user_id ASC,orders.created_at DESC", qry)
+}
+
+func TestItReturnsAnErrorIfDistinctOnIsNotSupportedOrCombinedWithDistinct(t *testing.T) {
+	var field1 string
+	assert := assert.New(t)
+
+	qry, err := NewSelect("table1").
+		ForMySQL().
+		DistinctOn("field1").
+		Select("field1").
+		Into(&field1).
+		GenerateQuery()
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportDistinctOn)
+	assert.Equal("", qry)
+
+	var unsupported ErrUnsupportedForDialect
+	assert.ErrorAs(err, &unsupported)
+	assert.Equal("DISTINCT ON", unsupported.Feature)
+	assert.Equal(MYSQL, unsupported.DB)
+
+	qry, err = NewSelect("table1").
+		ForPostgres().
+		Distinct().
+		DistinctOn("field1").
+		Select("field1").
+		Into(&field1).
+		GenerateQuery()
+	assert.Equal(ErrDistinctWithDistinctOn, err)
+	assert.Equal("", qry)
+}
+
+func TestItReturnsACopyOfTheColumnsAndTheTable(t *testing.T) {
+	var d struct {
+		field1 string
+		field2 string
+	}
+	qb := NewSelect("table1").
+		Select("field1", "table2.field2").
+		Into(&d.field1, &d.field2)
+	columns := qb.Columns()
+	columns[0] = "table1.field3"
+
+	assert := assert.New(t)
+	assert.Equal("table1", qb.Table())
+	assert.Equal([]string{"table1.field1", "table2.field2"}, qb.Columns())
+	assert.Nil(NewSelect("table1").Columns())
+
+	qb = NewUpdate("table1").
+		Set("field1").
+		To("value1").
+		SetRaw("field2", "field2+?", 1).
+		SoftDelete("deleted_at").
+		Where("table1.id", "=", 1)
+	assert.Equal([]string{"field1", "field2", "deleted_at"}, qb.Columns())
+}
+
+func TestItCreatesAnSQLStatementWithWhereInAndWhereBetween(t *testing.T) {
+	var field1 string
+	qb := NewSelect("table1").
+		ForPostgres().
+		Select("field1").
+		Into(&field1).
+		WhereIn("table1.field1", []interface{}{"a", "b"}).
+		WhereNotIn("table1.field2", []interface{}{1, 2, 3}).
+		WhereBetween("table1.field3", 10, 20)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 WHERE table1.field1 IN ($1,$2)"+
+		" AND table1.field2 NOT IN ($3,$4,$5) AND table1.field3 BETWEEN $6 AND $7", qry)
+	assert.Equal([]any{"a", "b", 1, 2, 3, 10, 20}, qb.Criteria())
+}
+
+func TestItCreatesAnAlwaysTruePredicateForAnEmptyNotIn(t *testing.T) {
+	var field1 string
+	qb := NewSelect("table1").
+		Select("field1").
+		Into(&field1).
+		WhereNotIn("table1.field2", nil)
+
+	assert := assert.New(t)
+	_, err := qb.GenerateQuery()
+	assert.Equal(ErrEmptyInValues, err)
+
+	qry, err := qb.EmptyInAsFalse().GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 WHERE 1=1", qry)
+}
+
+func TestItCreatesAnSQLStatementOrderedByOrdinal(t *testing.T) {
+	var d struct {
+		name  string
+		names string
+	}
+	qry, err := NewSelect("users").
+		AllowColumns("name", "nickname").
+		Select("name").
+		SelectCoalesce("nick", "nickname", "name").
+		Into(&d.name, &d.names).
+		OrderByOrdinal(2, 1).
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT users.name,COALESCE(users.nickname,users.name) AS nick FROM users ORDER BY 2 ASC,1 ASC", qry)
+
+	qry, err = NewSelect("users").
+		Select("name").
+		Into(&d.name).
+		OrderByOrdinal(0).
+		GenerateQuery()
+	assert.Equal(ErrInvalidOrdinal, err)
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAnSQLStatementOrderedByField(t *testing.T) {
+	var name string
+	qb := NewSelect("users").
+		Select("name").
+		Into(&name).
+		Where("users.active", "=", true).
+		OrderByField("users.id", 3, 1, 2)
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT users.name FROM users WHERE users.active=? ORDER BY FIELD(users.id,?,?,?) ASC", qry)
+	assert.Equal([]interface{}{true, 3, 1, 2}, args)
+
+	qry, args, err = qb.ForPostgres().Limit(10, 0).Build()
+	assert.Nil(err)
+	assert.Equal("SELECT users.name FROM users WHERE users.active=$1 ORDER BY CASE users.id WHEN $2 THEN 1 WHEN $3 THEN 2 WHEN $4 THEN 3 ELSE 0 END ASC LIMIT 10", qry)
+	assert.Equal([]interface{}{true, 3, 1, 2}, args)
+
+	_, err = NewSelect("users").
+		Select("name").
+		Into(&name).
+		OrderByField("users.id").
+		GenerateQuery()
+	assert.Equal(ErrEmptyOrderByField, err)
+}
+
+func TestItCreatesAnSQLStatementForOracleWithLegacyJoins(t *testing.T) {
+	var d struct {
+		field1 string
+		field2 string
+	}
+	qb := NewSelect("table1").
+		ForOracle().
+		LegacyOracleJoins().
+		Select("field1", "table2.field2").
+		Into(&d.field1, &d.field2).
+		LeftJoin("table2", "table2.table1_id", "table1.id").
+		RightJoin("table3", "table1.table3_id", "table3.id").
+		InnerJoin("table4", "table4.id", "table1.table4_id").
+		Where("table1.field1", "=", "value1").
+		OrWhere("table1.field1", "=", "value2")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1,table2.field2 FROM table1,table2,table3,table4"+
+		" WHERE table2.table1_id(+)=table1.id AND table1.table3_id(+)=table3.id"+
+		" AND table4.id=table1.table4_id AND (table1.field1=:1 OR table1.field1=:2)", qry)
+	assert.Equal([]any{"value1", "value2"}, qb.Criteria())
+}
+
+func TestItReturnsAnErrorIfLegacyJoinsAreNotSupported(t *testing.T) {
+	var field1 string
+	assert := assert.New(t)
+
+	qry, err := NewSelect("table1").
+		ForPostgres().
+		LegacyOracleJoins().
+		Select("field1").
+		Into(&field1).
+		LeftJoin("table2", "table2.table1_id", "table1.id").
+		GenerateQuery()
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportLegacyJoins)
+	assert.Equal("", qry)
+
+	qry, err = NewSelect("table1").
+		ForOracle().
+		LegacyOracleJoins().
+		Select("field1").
+		Into(&field1).
+		Join("FULL", "table2", "table2.table1_id", "table1.id").
+		GenerateQuery()
+	assert.Equal(ErrLegacyJoinNotSupported, err)
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAnSQLStatementWithFunctionExpressions(t *testing.T) {
+	var d struct {
+		count int
+		name  string
+	}
+	qb := NewSelect("users").
+		ForPostgres().
+		Select("COUNT(*)", "LOWER(users.name)").
+		Into(&d.count, &d.name).
+		Where("LOWER(users.name)", "=", "value1").
+		Where("DATE(users.created_at)", "BETWEEN", "2024-01-01", "2024-12-31").
+		Where("LENGTH(users.name)", "IN", 3, 4)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT COUNT(*),LOWER(users.name) FROM users WHERE LOWER(users.name)=$1"+
+		" AND DATE(users.created_at) BETWEEN $2 AND $3 AND LENGTH(users.name) IN ($4,$5)", qry)
+	assert.Equal([]any{"value1", "2024-01-01", "2024-12-31", 3, 4}, qb.Criteria())
+}
+
+func TestItGeneratesEveryOperatorAsDefinedInTheOperatorsTable(t *testing.T) {
+	tests := []struct {
+		operator string
+		values   []interface{}
+		expected string
+	}{
+		{"=", []interface{}{1}, "table1.field1=?"},
+		{">", []interface{}{1}, "table1.field1>?"},
+		{"<", []interface{}{1}, "table1.field1<?"},
+		{">=", []interface{}{1}, "table1.field1>=?"},
+		{"<=", []interface{}{1}, "table1.field1<=?"},
+		{"<>", []interface{}{1}, "table1.field1<>?"},
+		{"!=", []interface{}{1}, "table1.field1<>?"},
+		{"in", []interface{}{1, 2}, "table1.field1 IN (?,?)"},
+		{"Not In", []interface{}{1, 2}, "table1.field1 NOT IN (?,?)"},
+		{"between", []interface{}{1, 2}, "table1.field1 BETWEEN ? AND ?"},
+		{"not between", []interface{}{1, 2}, "table1.field1 NOT BETWEEN ? AND ?"},
+		{"like", []interface{}{"a%"}, "table1.field1 LIKE ?"},
+		{"not like", []interface{}{"a%"}, "table1.field1 NOT LIKE ?"},
+	}
+
+	assert := assert.New(t)
+	for _, test := range tests {
+		qry, err := NewDelete("table1").
+			Where("table1.field1", test.operator, test.values...).
+			GenerateQuery()
+		assert.Nil(err)
+		assert.Equal("DELETE FROM table1 WHERE "+test.expected, qry)
+	}
+}
+
+func TestItReturnsAnErrorIfASingleValueOperatorDoesNotHaveOneValue(t *testing.T) {
+	assert := assert.New(t)
+
+	qb := NewDelete("table1").Where("table1.field1", "=")
+	assert.Equal(ErrBadOperatorValues, qb.Err())
+
+	qb = NewDelete("table1").Where("table1.field1", "LIKE", "a%", "b%")
+	qry, err := qb.GenerateQuery()
+	assert.Equal(ErrBadOperatorValues, err)
+	assert.Equal("", qry)
+}
+
+func TestItCreatesAnInsertStatementWithDefaultValues(t *testing.T) {
+	qb := NewInsert("table1").
+		ForPostgres().
+		Set("field1", "created_at", "field2").
+		To("value1", Default, 2)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("INSERT INTO table1 (field1,created_at,field2) VALUES ($1,DEFAULT,$2)", qry)
+	assert.Equal([]any{"value1", 2}, qb.Args())
+
+	qry, err = qb.ForSQLite().GenerateQuery()
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportDefault)
+	assert.Equal("", qry)
+}
+
+func TestItCreatesABulkUpdateWithCaseInAStableOrder(t *testing.T) {
+	mapping := map[interface{}]interface{}{10: 100, 2: 20, 1: 10, 33: 330}
+	assert := assert.New(t)
+	for i := 0; i < 10; i++ {
+		qb := NewUpdate("table1").
+			ForPostgres().
+			SetCase("price", "id", mapping).
+			WhereIn("table1.id", []interface{}{1, 2, 10, 33})
+		qry, err := qb.GenerateQuery()
+
+		assert.Nil(err)
+		assert.Equal("UPDATE table1 SET price=CASE id WHEN $1 THEN $2 WHEN $3 THEN $4 WHEN $5 THEN $6"+
+			" WHEN $7 THEN $8 ELSE price END WHERE table1.id IN ($9,$10,$11,$12)", qry)
+		assert.Equal([]any{1, 10, 2, 20, 10, 100, 33, 330, 1, 2, 10, 33}, qb.Args())
+	}
+
+	qb := NewUpdate("table1").SetCase("price", "id", map[interface{}]interface{}{"b": 2, "a": 1})
+	qry, err := qb.GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("UPDATE table1 SET price=CASE id WHEN ? THEN ? WHEN ? THEN ? ELSE price END", qry)
+	assert.Equal([]any{"a", 1, "b", 2}, qb.Args())
+
+	mixed := map[interface{}]interface{}{"a": 5, 1.0: 4, true: 6, int64(1): 3, uint8(1): 2, 1: 1}
+	for i := 0; i < 10; i++ {
+		qb = NewUpdate("table1").SetCase("price", "id", mixed)
+		assert.Nil(qb.Err())
+		assert.Equal([]any{1.0, 4, 1, 1, int64(1), 3, uint8(1), 2, "a", 5, true, 6}, qb.Args())
+	}
+
+	qb = NewUpdate("table1").SetCase("price", "id", nil)
+	assert.Equal(ErrEmptyCaseMapping, qb.Err())
+}
+
+func TestItCreatesAnSQLStatementWithAJoinCondition(t *testing.T) {
+	var d struct {
+		id   int
+		rate float64
+	}
+	qb := NewSelect("orders").
+		ForPostgres().
+		Select("id", "rates.rate").
+		Into(&d.id, &d.rate).
+		JoinCond("INNER", "rates", "rates.start<=orders.ts AND rates.end>orders.ts AND rates.currency=?", "EUR").
+		Where("orders.id", "=", 1)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT orders.id,rates.rate FROM orders INNER JOIN rates"+
+		" ON rates.start<=orders.ts AND rates.end>orders.ts AND rates.currency=$1 WHERE orders.id=$2", qry)
+	assert.Equal([]any{"EUR", 1}, qb.Criteria())
+	assert.Equal([]any{"EUR", 1}, qb.Args())
+}
+
+func TestItCreatesAnUpdateStatementWithAJoinCondition(t *testing.T) {
+	assert := assert.New(t)
+
+	qb := NewUpdate("orders").
+		ForMySQL().
+		Set("orders.rate").
+		To(2).
+		JoinCond("INNER", "rates", "rates.currency=orders.currency AND rates.day=?", "2024-01-01").
+		Where("orders.id", "=", 1)
+	qry, err := qb.GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("UPDATE orders INNER JOIN rates ON rates.currency=orders.currency AND rates.day=?"+
+		" SET orders.rate=? WHERE orders.id=?", qry)
+	assert.Equal([]any{"2024-01-01", 2, 1}, qb.Args())
+
+	qry, err = qb.ForPostgres().GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("UPDATE orders SET orders.rate=$1 FROM rates"+
+		" WHERE (rates.currency=orders.currency AND rates.day=$2) AND orders.id=$3", qry)
+	assert.Equal([]any{2, "2024-01-01", 1}, qb.Args())
+}
+
+func TestItReturnsAnErrorIfJoinConditionPlaceholdersDoNotMatchValues(t *testing.T) {
+	qb := NewSelect("orders").
+		SelectAll().
+		JoinCond("INNER", "rates", "rates.currency=?")
+
+	assert := assert.New(t)
+	assert.IsType(ErrBadPlaceholdersValuesCombo{}, qb.Err())
+}
+
+func TestItBuildsJoinArgsBeforeWhereArgs(t *testing.T) {
+	var d struct {
+		id    int
+		total float64
+	}
+	recent := NewSelect("orders").
+		Select("id").
+		Where("orders.created_at", ">", "2024-01-01")
+	qry, args, err := NewSelect("users").
+		ForPostgres().
+		With("recent", recent).
+		Select("id", "totals.total").
+		Into(&d.id, &d.total).
+		JoinCond("INNER", "totals", "totals.user_id=users.id AND totals.year=?", 2024).
+		LeftJoin("teams", "teams.id", "users.team_id").
+		JoinCond("LEFT", "bans", "bans.user_id=users.id AND bans.until>?", "2024-06-01").
+		Where("users.active", "=", true).
+		WhereGroup(func(gb *Builder) {
+			gb.Where("users.age", ">", 18).OrWhere("users.role", "=", "admin")
+		}).
+		Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("WITH recent AS (SELECT orders.id FROM orders WHERE orders.created_at>$1)"+
+		" SELECT users.id,totals.total FROM users"+
+		" INNER JOIN totals ON totals.user_id=users.id AND totals.year=$2"+
+		" LEFT JOIN teams ON teams.id=users.team_id"+
+		" LEFT JOIN bans ON bans.user_id=users.id AND bans.until>$3"+
+		" WHERE users.active=$4 AND (users.age>$5 OR users.role=$6)", qry)
+	assert.Equal([]any{"2024-01-01", 2024, "2024-06-01", true, 18, "admin"}, args)
+}
+
+func TestItValidatesABuilderWithTheErrorsOfGenerateQuery(t *testing.T) {
+	var field1 string
+	assert := assert.New(t)
+
+	assert.Nil(NewSelect("table1").Select("field1").Into(&field1).Where("table1.id", "=", 1).Validate())
+	assert.Equal(ErrEmptyTableName, NewSelect("").Select("field1").Into(&field1).Validate())
+	assert.IsType(ErrInvalidSqlOperator{}, NewSelect("table1").Where("table1.id", "==", 1).Validate())
+	assert.IsType(ErrBadColumnsValuesCombo{}, NewSelect("table1").Select("field1", "field2").Into(&field1).Validate())
+	assert.Equal(ErrDBEngineDoesNotSupportReturning, NewDelete("table1").ForMySQL().Returning("id").Into(&field1).Validate())
+}
+
+func TestItValidatesAQueryWithoutChangingItsSubqueries(t *testing.T) {
+	sub := NewSelect("table2").Select("table1_id").Where("table2.field2", "=", 2)
+	qb := NewSelect("table1").
+		ForPostgres().
+		Select("field1").
+		Where("table1.field1", "=", 1).
+		WhereInSubquery("table1.id", sub)
+
+	assert := assert.New(t)
+	assert.Nil(qb.Validate())
+	qry, err := sub.GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table2.table1_id FROM table2 WHERE table2.field2=?", qry)
+}
+
+func TestItCreatesAnSQLStatementWithWhereNotLike(t *testing.T) {
+	var field1 string
+	qb := NewSelect("table1").
+		ForSQLite().
+		Select("field1").
+		Into(&field1).
+		WhereNotLike("table1.field1", "%"+EscapeLike("50%")+"%")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal(`SELECT table1.field1 FROM table1 WHERE table1.field1 NOT LIKE ? ESCAPE '\'`, qry)
+	assert.Equal([]any{`%50\%%`}, qb.Criteria())
+
+	qb = NewSelect("table1").Where("table1.field1", "NOT LIKE")
+	assert.Equal(ErrBadOperatorValues, qb.Err())
+}
+
+func TestItCreatesAnSQLStatementWithGreatestAndLeast(t *testing.T) {
+	var d struct {
+		latest   string
+		earliest string
+	}
+	tests := []struct {
+		db       database
+		expected string
+	}{
+		{MYSQL, "SELECT GREATEST(table1.created_at,table1.updated_at) AS latest,LEAST(table1.created_at,table2.created_at) AS earliest FROM table1"},
+		{POSTGRES, "SELECT GREATEST(table1.created_at,table1.updated_at) AS latest,LEAST(table1.created_at,table2.created_at) AS earliest FROM table1"},
+		{ORACLE, "SELECT GREATEST(table1.created_at,table1.updated_at) AS latest,LEAST(table1.created_at,table2.created_at) AS earliest FROM table1"},
+		{SQLITE, "SELECT MAX(table1.created_at,table1.updated_at) AS latest,MIN(table1.created_at,table2.created_at) AS earliest FROM table1"},
+	}
+
+	assert := assert.New(t)
+	for _, test := range tests {
+		qry, err := NewSelect("table1").
+			ForDatabase(test.db).
+			SelectGreatest("latest", "created_at", "updated_at").
+			SelectLeast("earliest", "created_at", "table2.created_at").
+			Into(&d.latest, &d.earliest).
+			GenerateQuery()
+		assert.Nil(err)
+		assert.Equal(test.expected, qry)
+	}
+
+	_, err := NewSelect("table1").
+		ForSQLServer().
+		SelectGreatest("latest", "created_at", "updated_at").
+		GenerateQuery()
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportGreatestLeast)
+
+	latest := NewSelect("table1").SelectGreatest("latest", "created_at", "updated_at")
+	qry, err := NewSelect("recent").
+		ForSQLite().
+		With("recent", latest).
+		Select("latest").
+		GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("WITH recent AS (SELECT MAX(table1.created_at,table1.updated_at) AS latest FROM table1)"+
+		" SELECT recent.latest FROM recent", qry)
+}
+
+func TestItCreatesAnSQLStatementWithoutScanDestinations(t *testing.T) {
+	var field1 string
+	qb := NewSelect("table1").
+		ForPostgres().
+		Select("field3").
+		Into(&field1).
+		SelectColumns("field1", "table2.field2").
+		Where("table1.field1", "=", "value1")
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1,table2.field2 FROM table1 WHERE table1.field1=$1", qry)
+	assert.Equal([]any{"value1"}, args)
+	assert.Nil(qb.Values())
+
+	qry, err = NewSelect("table1").Select("field1", "field2").GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1,table1.field2 FROM table1", qry)
+}
+
+func TestItParsesTheNamesOfTheDatabaseEngines(t *testing.T) {
+	assert := assert.New(t)
+	for _, db := range []database{MYSQL, SQLITE, POSTGRES, ORACLE, SQLSERVER} {
+		parsed, err := ParseDatabase(db.String())
+		assert.Nil(err)
+		assert.Equal(db, parsed)
+	}
+
+	db, err := ParseDatabase("Postgres")
+	assert.Nil(err)
+	assert.Equal(POSTGRES, db)
+	assert.Equal("postgres", db.String())
+
+	_, err = ParseDatabase("db2")
+	assert.Equal(NewUnknownDatabaseError("db2"), err)
+	assert.Equal("database(9)", database(9).String())
+}
+
+func TestItNamesTheQueryTypes(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("select", NewSelect("table1").queryType.String())
+	assert.Equal("insert", insertQry.String())
+	assert.Equal("update", updateQry.String())
+	assert.Equal("delete", deleteQry.String())
+	assert.Equal("truncate", truncateQry.String())
+}
+
+func TestItCreatesAnSQLStatementWithBooleanLiterals(t *testing.T) {
+	expected := map[database]string{
+		MYSQL:     "DELETE FROM table1 WHERE table1.active=1 AND table1.deleted=0 AND table1.field1=?",
+		SQLITE:    "DELETE FROM table1 WHERE table1.active=1 AND table1.deleted=0 AND table1.field1=?",
+		POSTGRES:  "DELETE FROM table1 WHERE table1.active=TRUE AND table1.deleted=FALSE AND table1.field1=$1",
+		ORACLE:    "DELETE FROM table1 WHERE table1.active=1 AND table1.deleted=0 AND table1.field1=:1",
+		SQLSERVER: "DELETE FROM table1 WHERE table1.active=1 AND table1.deleted=0 AND table1.field1=@p1",
+	}
+
+	assert := assert.New(t)
+	for db, expectedQry := range expected {
+		qb := NewDelete("table1").
+			ForDatabase(db).
+			WhereBool("table1.active", true).
+			WhereBool("table1.deleted", false).
+			Where("table1.field1", "=", "value1")
+		qry, args, err := qb.Build()
+		assert.Nil(err, db)
+		assert.Equal(expectedQry, qry, db)
+		assert.Equal([]interface{}{"value1"}, args, db)
+	}
+}
+
+func TestItCreatesAnSQLStatementWithGroupByAndHaving(t *testing.T) {
+	var d struct {
+		country string
+		count   int
+	}
+	qb := NewSelect("users").
+		ForPostgres().
+		Select("country").
+		Select("COUNT(*)").
+		Into(&d.country, &d.count).
+		Where("users.active", "=", true).
+		GroupBy("country").
+		Having("COUNT(*)", ">", 10).
+		OrHaving("MAX(users.age)", "<", 30).
+		OrderBy("users.country")
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT users.country,COUNT(*) FROM users WHERE users.active=$1 GROUP BY users.country HAVING COUNT(*)>$2 OR MAX(users.age)<$3 ORDER BY users.country ASC", qry)
+	assert.Equal([]interface{}{true, 10, 30}, args)
+	assert.Equal(args, qb.Criteria())
+
+	_, err = qb.CountQuery()
+	assert.Equal(ErrCountQueryWithGroupBy, err)
+
+	_, err = NewSelect("users").
+		Select("country").
+		Into(&d.country).
+		Having("COUNT(*)", ">", 10).
+		GenerateQuery()
+	assert.Equal(ErrHavingWithoutGroupBy, err)
+}
+
+func TestItCreatesAnSQLStatementWithAScalarSubqueryComparison(t *testing.T) {
+	var name string
+	qb := NewSelect("products").
+		ForPostgres().
+		Select("name").
+		Into(&name).
+		Where("products.category", "=", "books").
+		WhereSubquery("products.price", ">", NewSelect("sales").
+			Select("AVG(sales.price)").
+			Where("sales.year", "=", 2024)).
+		Where("products.stock", ">", 0)
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT products.name FROM products WHERE products.category=$1"+
+		" AND products.price > (SELECT AVG(sales.price) FROM sales WHERE sales.year=$2) AND products.stock>$3", qry)
+	assert.Equal([]interface{}{"books", 2024, 0}, args)
+
+	for _, operator := range []string{"IN", "BETWEEN", "LIKE"} {
+		qb := NewSelect("products").
+			Select("name").
+			Into(&name).
+			WhereSubquery("products.price", operator, NewSelect("sales").Select("AVG(sales.price)"))
+		assert.Equal(NewInvalidOperatorError(operator), qb.Err(), operator)
+	}
+}
+
+func TestItCreatesAnSQLStatementWithARowValueIn(t *testing.T) {
+	qb := NewDelete("table1").
+		ForPostgres().
+		Where("table1.field1", "=", "value1").
+		WhereRowIn([]string{"table1.a", "table1.b"}, [][]interface{}{{1, 2}, {3, 4}})
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("DELETE FROM table1 WHERE table1.field1=$1 AND (table1.a,table1.b) IN (($2,$3),($4,$5))", qry)
+	assert.Equal([]interface{}{"value1", 1, 2, 3, 4}, args)
+
+	_, err = qb.ForSQLServer().GenerateQuery()
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportRowValues)
+
+	qb = NewDelete("table1").WhereRowIn([]string{"table1.a", "table1.b"}, [][]interface{}{{1, 2}, {3}})
+	assert.Equal(NewBadColumnsValuesComboError(2, 1), qb.Err())
+
+	qry, err = NewDelete("table1").
+		WhereRowIn([]string{"table1.a", "table1.b"}, nil).
+		EmptyInAsFalse().
+		GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("DELETE FROM table1 WHERE 1=0", qry)
+}
+
+func TestItPrefixesTheColumnsOfTheOrder(t *testing.T) {
+	var d struct {
+		field1 string
+		nick   string
+	}
+	qry, err := NewSelect("table1").
+		Select("field1").
+		SelectCoalesce("nick", "nickname", "field1").
+		Into(&d.field1, &d.nick).
+		Join("LEFT", "table2", "table2.table1_id", "table1.id").
+		OrderBy("field1").
+		OrderByDescending("table2.field1").
+		OrderBy("nick").
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1,COALESCE(table1.nickname,table1.field1) AS nick FROM table1"+
+		" LEFT JOIN table2 ON table2.table1_id=table1.id ORDER BY table1.field1 ASC,table2.field1 DESC,nick ASC", qry)
+}
+
+func TestItCreatesAnSQLStatementWithARawHaving(t *testing.T) {
+	var d struct {
+		country string
+		count   int
+	}
+	qb := NewSelect("users").
+		ForPostgres().
+		Select("country", "COUNT(*)").
+		Into(&d.country, &d.count).
+		Where("users.active", "=", true).
+		GroupBy("country").
+		HavingRaw("COUNT(*) > ?", 10).
+		Having("MAX(users.age)", "<", 30)
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT users.country,COUNT(*) FROM users WHERE users.active=$1 GROUP BY users.country HAVING (COUNT(*) > $2) AND MAX(users.age)<$3", qry)
+	assert.Equal([]interface{}{true, 10, 30}, args)
+
+	qb = NewSelect("users").GroupBy("country").HavingRaw("COUNT(*) > ?")
+	assert.Equal(NewBadPlaceholdersValuesComboError(1, 0), qb.Err())
+}
+
+func TestItWrapsARawHavingWithAnORInParentheses(t *testing.T) {
+	qry, err := NewSelect("users").
+		ForPostgres().
+		Select("country").
+		GroupBy("country").
+		Having("MAX(users.age)", "<", 30).
+		HavingRaw("COUNT(*) > ? OR SUM(users.score) > ?", 10, 100).
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT users.country FROM users GROUP BY users.country"+
+		" HAVING MAX(users.age)<$1 AND (COUNT(*) > $2 OR SUM(users.score) > $3)", qry)
+}
+
+func TestItCreatesAnSQLStatementWithAllowedOperators(t *testing.T) {
+	qb := NewDelete("table1").
+		ForPostgres().
+		AllowOperators("@>", "~").
+		Where("table1.data", "@>", `{"a":1}`).
+		WhereGroup(func(g *Builder) {
+			g.Where("table1.name", "~", "^a").OrWhere("table1.id", "=", 1)
+		})
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("DELETE FROM table1 WHERE table1.data@>$1 AND (table1.name~$2 OR table1.id=$3)", qry)
+	assert.Equal([]interface{}{`{"a":1}`, "^a", 1}, args)
+
+	qb = NewDelete("table1").Where("table1.data", "@>", `{"a":1}`)
+	assert.Equal(NewInvalidOperatorError("@>"), qb.Err())
+
+	qry, err = NewDelete("table1").
+		ForPostgres().
+		AllowOperators("similar to", "@@").
+		Where("table1.name", "SIMILAR TO", "(a|b)%").
+		Where("table1.body", "@@", "cat & dog").
+		GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("DELETE FROM table1 WHERE table1.name SIMILAR TO $1 AND table1.body@@$2", qry)
+}
+
+func TestItCreatesAnSQLStatementWithJSONCriteria(t *testing.T) {
+	qb := NewDelete("table1").
+		ForPostgres().
+		WhereJSONField("table1.data", "name", "=", "John").
+		WhereJSONField("data", "it's", "LIKE", "a%").
+		WhereJSONContains("table1.data", `{"tags":["a"]}`)
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal(`DELETE FROM table1 WHERE table1.data->>'name'=$1 AND data->>'it''s' LIKE $2 AND table1.data@>$3`, qry)
+	assert.Equal([]interface{}{"John", "a%", `{"tags":["a"]}`}, args)
+
+	_, err = qb.ForMySQL().GenerateQuery()
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportJSONOperators)
+}
+
+func TestItCreatesAnSQLStatementWithASelectedSubquery(t *testing.T) {
+	var d struct {
+		id         int
+		orderCount int
+	}
+	qb := NewSelect("users").
+		ForPostgres().
+		With("recent", NewSelect("orders").Select("id").Where("orders.year", "=", 2024)).
+		Select("id").
+		SelectSubquery(NewSelect("orders").
+			Select("COUNT(*)").
+			WhereRaw("orders.user_id=users.id").
+			Where("orders.status", "=", "paid"), "order_count").
+		Into(&d.id, &d.orderCount).
+		Where("users.active", "=", true)
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("WITH recent AS (SELECT orders.id FROM orders WHERE orders.year=$1)"+
+		" SELECT users.id,(SELECT COUNT(*) FROM orders WHERE (orders.user_id=users.id) AND orders.status=$2) AS order_count"+
+		" FROM users WHERE users.active=$3", qry)
+	assert.Equal([]interface{}{2024, "paid", true}, args)
+	assert.Equal([]string{"users.id", "(subquery) AS order_count"}, qb.Columns())
+}
+
+func TestItReturnsAnErrorForSelectedSubqueriesWithTheSameAlias(t *testing.T) {
+	_, err := NewSelect("users").
+		Select("id").
+		SelectSubquery(NewSelect("orders").Select("COUNT(*)"), "total").
+		SelectSubquery(NewSelect("payments").Select("COUNT(*)"), "total").
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Equal(NewDuplicateAliasError("total"), err)
+}
+
+func TestItCreatesAnSQLStatementOrderedByManySpecs(t *testing.T) {
+	var field1 string
+	specs := []OrderSpec{
+		{Column: "field1"},
+		{Column: "table1.field2", Descending: true},
+	}
+	qb := NewSelect("table1").
+		AllowColumns("field1", "field2").
+		Select("field1").
+		Into(&field1).
+		OrderByMany(specs)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 ORDER BY table1.field1 ASC,table1.field2 DESC", qry)
+
+	_, err = qb.OrderByMany([]OrderSpec{{Column: "field3"}}).GenerateQuery()
+	assert.Equal(NewDisallowedColumnError("field3"), err)
+}
+
+func TestItCreatesAnSQLStatementWithLimitZero(t *testing.T) {
+	var field1 string
+	qb := NewSelect("table1").
+		Select("field1").
+		Into(&field1).
+		Limit(0, 0)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1", qry)
+
+	qry, err = qb.LimitZero().GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 LIMIT 0", qry)
+
+	qry, err = qb.ForPostgres().Limit(10, 0).GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 LIMIT 10", qry)
+
+	qry, err = qb.ForMySQL().Limit(0, 30).LimitZero().GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 LIMIT 0 OFFSET 30", qry)
+
+	qry, err = qb.ForPostgres().GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT table1.field1 FROM table1 LIMIT 0 OFFSET 30", qry)
+
+	_, err = qb.ForSQLServer().OrderBy("field1").LimitZero().GenerateQuery()
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportLimitZero)
+}
+
+func TestItReturnsAnErrorIfThePageOffsetOverflows(t *testing.T) {
+	qb := NewSelect("table1").Page(math.MaxUint/10+2, 10)
+
+	assert := assert.New(t)
+	assert.Equal(ErrPageOutOfRange, qb.Err())
+}
+
+func TestItCreatesAnSQLStatementWithACountOfDistinctValues(t *testing.T) {
+	var d struct {
+		country string
+		cities  int
+	}
+	qry, err := NewSelect("users").
+		AllowColumns("country", "city").
+		Select("country").
+		CountDistinct("city", "cities").
+		Into(&d.country, &d.cities).
+		GroupBy("country").
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT users.country,COUNT(DISTINCT users.city) AS cities FROM users GROUP BY users.country", qry)
+
+	_, err = NewSelect("users").
+		CountDistinct("city", "cities").
+		Into(&d.country, &d.cities).
+		GenerateQuery()
+	assert.Equal(NewBadColumnsValuesComboError(1, 2), err)
+}
+
+func TestItCreatesAnSQLStatementComparingColumns(t *testing.T) {
+	var id int
+	qb := NewSelect("table1").
+		ForPostgres().
+		Select("id").
+		Into(&id).
+		Join("INNER", "table2", "table2.table1_id", "table1.id").
+		Where("table1.field1", "=", "value1").
+		WhereColumn("created_at", "<", "table2.updated_at").
+		OrWhereColumn("table1.field2", "!=", "field3")
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT table1.id FROM table1 INNER JOIN table2 ON table2.table1_id=table1.id"+
+		" WHERE table1.field1=$1 AND table1.created_at<table2.updated_at OR table1.field2<>table1.field3", qry)
+	assert.Equal([]interface{}{"value1"}, args)
+
+	qb = NewSelect("table1").WhereColumn("field1", "IN", "field2")
+	assert.Equal(NewInvalidOperatorError("IN"), qb.Err())
+}
+
+func TestItPrefixesTheComparedColumnsWhenTheQueryIsGenerated(t *testing.T) {
+	var id int
+	qry, err := NewSelect("table1").
+		WhereColumn("field1", "<", "field2").
+		DisableAutoPrefix().
+		Select("id").
+		Into(&id).
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT id FROM table1 WHERE field1<field2", qry)
+
+	qry, err = NewSelect("table1").
+		WhereColumn("field1", "<", "field2").
+		FromSubquery(NewSelect("table2").Select("id", "field1", "field2"), "sub").
+		Select("id").
+		Into(&id).
+		GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT sub.id FROM (SELECT table2.id,table2.field1,table2.field2 FROM table2) sub"+
+		" WHERE sub.field1<sub.field2", qry)
+}
+
+func TestItCreatesAnSQLStatementWithTies(t *testing.T) {
+	var name string
+	qb := NewSelect("players").
+		ForPostgres().
+		Select("name").
+		Into(&name).
+		OrderByDescending("score").
+		Limit(3, 0).
+		WithTies()
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT players.name FROM players ORDER BY players.score DESC FETCH FIRST 3 ROWS WITH TIES", qry)
+
+	qry, err = qb.Page(2, 3).GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT players.name FROM players ORDER BY players.score DESC OFFSET 3 ROWS FETCH FIRST 3 ROWS WITH TIES", qry)
+
+	_, err = qb.ForMySQL().GenerateQuery()
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportWithTies)
+
+	_, err = NewSelect("players").ForPostgres().Select("name").Into(&name).Limit(3, 0).WithTies().GenerateQuery()
+	assert.Equal(ErrWithTiesWithoutOrderBy, err)
+
+	_, err = NewSelect("players").ForOracle().Select("name").Into(&name).OrderBy("score").WithTies().GenerateQuery()
+	assert.Equal(ErrWithTiesWithoutLimit, err)
+}
+
+func TestItCreatesASoftDeleteStatement(t *testing.T) {
+	expected := map[database]string{
+		MYSQL:     "UPDATE users SET deleted_at=NOW() WHERE users.id=?",
+		SQLITE:    "UPDATE users SET deleted_at=CURRENT_TIMESTAMP WHERE users.id=?",
+		POSTGRES:  "UPDATE users SET deleted_at=now() WHERE users.id=$1",
+		ORACLE:    "UPDATE users SET deleted_at=SYSDATE WHERE users.id=:1",
+		SQLSERVER: "UPDATE users SET deleted_at=CURRENT_TIMESTAMP WHERE users.id=@p1",
+	}
+
+	assert := assert.New(t)
+	for db, expectedQry := range expected {
+		qry, args, err := NewUpdate("users").
+			ForDatabase(db).
+			SoftDelete("deleted_at").
+			Where("users.id", "=", 1).
+			Build()
+		assert.Nil(err, db)
+		assert.Equal(expectedQry, qry, db)
+		assert.Equal([]interface{}{1}, args, db)
+	}
+
+	qb := NewDelete("users").SoftDelete("deleted_at")
+	assert.Equal(ErrNotUpdateQuery, qb.Err())
+}
+
+func TestItCreatesAnInsertStatementReturningAllColumns(t *testing.T) {
+	var d struct {
+		id     int
+		field1 string
+	}
+	qry, err := NewInsert("table1").
+		ForPostgres().
+		AllowColumns("field1").
+		Set("field1").
+		To("value1").
+		ReturningAll().
+		Into(&d.id, &d.field1).
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("INSERT INTO table1 (field1) VALUES ($1) RETURNING *", qry)
+}
+
+func TestItCreatesAnSQLStatementWithATableTemplate(t *testing.T) {
+	var id int
+	qry, err := NewSelect("").
+		TableTemplate("tenant_{ident}.events", "42").
+		Select("id").
+		Into(&id).
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT tenant_42.events.id FROM tenant_42.events", qry)
+
+	for _, ident := range []string{"", "a.b", "1;DROP TABLE users", "a b", "x*"} {
+		_, err = NewDelete("").TableTemplate("tenant_{ident}.events", ident).GenerateQuery()
+		assert.Equal(NewInvalidIdentifierError(ident), err, ident)
+	}
+}
+
+func TestItCreatesAnSQLStatementWithIndexHints(t *testing.T) {
+	var id int
+	qb := NewSelect("table1").
+		Select("id").
+		Into(&id).
+		UseIndex("idx_field1").
+		ForceIndex("idx_field2").
+		Join("INNER", "table2", "table2.table1_id", "table1.id").
+		Where("table1.field1", "=", "value1")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT table1.id FROM table1 USE INDEX (idx_field1) FORCE INDEX (idx_field2)"+
+		" INNER JOIN table2 ON table2.table1_id=table1.id WHERE table1.field1=?", qry)
+
+	_, err = qb.ForPostgres().GenerateQuery()
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportIndexHints)
+
+	_, err = NewSelect("table1").Select("id").Into(&id).UseIndex("idx) DROP").GenerateQuery()
+	assert.Equal(NewInvalidIdentifierError("idx) DROP"), err)
+}
+
+func TestItCreatesAnSQLStatementWithTheLegacyOraclePagination(t *testing.T) {
+	var d struct {
+		name string
+		rnum int
+	}
+	qb := NewSelect("users").
+		ForOracle().
+		OracleLegacyPagination().
+		Select("name").
+		Into(&d.name, &d.rnum).
+		Where("users.active", "=", 1).
+		OrderBy("name").
+		Page(3, 10)
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT * FROM (SELECT a.*,ROWNUM rnum FROM (SELECT users.name FROM users WHERE users.active=:1 ORDER BY users.name ASC) a"+
+		" WHERE ROWNUM<=:2) WHERE rnum>:3", qry)
+	assert.Equal([]interface{}{1, uint(30), uint(20)}, args)
+
+	_, err = NewSelect("users").
+		ForPostgres().
+		OracleLegacyPagination().
+		Select("name").
+		Into(&d.name).
+		Limit(10, 0).
+		GenerateQuery()
+	assert.ErrorIs(err, ErrDBEngineDoesNotSupportLegacyPagination)
+}
+
+func TestItCreatesAnSQLStatementWithCriteriaFromAMap(t *testing.T) {
+	qb := NewDelete("table1").
+		ForPostgres().
+		Where("table1.id", ">", 10).
+		WhereMap(map[string]interface{}{
+			"table1.field3": true,
+			"table1.field1": "value1",
+			"table1.field2": 2,
+		})
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("DELETE FROM table1 WHERE table1.id>$1 AND table1.field1=$2 AND table1.field2=$3 AND table1.field3=$4", qry)
+	assert.Equal([]interface{}{10, "value1", 2, true}, qb.Criteria())
+}
+
+func TestItReturnsThePlaceholdersOfEachDatabaseEngine(t *testing.T) {
+	expected := map[database][]string{
+		MYSQL:     {"?", "?"},
+		SQLITE:    {"?", "?"},
+		POSTGRES:  {"$1", "$12"},
+		ORACLE:    {":1", ":12"},
+		SQLSERVER: {"@p1", "@p12"},
+	}
+
+	assert := assert.New(t)
+	for db, placeholders := range expected {
+		assert.Equal(placeholders, []string{Placeholder(db, 1), Placeholder(db, 12)}, db)
+	}
+}
+
+func TestItReturnsAnErrorIfAnOffsetIsSetWithoutALimit(t *testing.T) {
+	var field1 string
+	qb := NewSelect("table1").
+		Select("field1").
+		Into(&field1).
+		Limit(0, 20)
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Equal(ErrOffsetWithoutLimit, err)
+	assert.Equal("", qry)
+
+	expected := map[database]string{
+		MYSQL:    "SELECT table1.field1 FROM table1 LIMIT 0 OFFSET 20",
+		SQLITE:   "SELECT table1.field1 FROM table1 LIMIT 0 OFFSET 20",
+		POSTGRES: "SELECT table1.field1 FROM table1 LIMIT 0 OFFSET 20",
+		ORACLE:   "SELECT table1.field1 FROM table1 OFFSET 20 ROWS FETCH NEXT 0 ROWS ONLY",
+	}
+	for db, expectedQry := range expected {
+		qry, err = qb.ForDatabase(db).LimitZero().GenerateQuery()
+		assert.Nil(err, db)
+		assert.Equal(expectedQry, qry, db)
+	}
+}
+
+func TestItCreatesAnSQLStatementWithConditions(t *testing.T) {
+	qb := NewDelete("table1").
+		ForPostgres().
+		WhereAllOf(
+			Condition{Column: "table1.field1", Operator: "=", Values: []interface{}{"value1"}},
+			Condition{Column: "table1.field2", Operator: "IN", Values: []interface{}{1, 2}},
+		).
+		WhereAnyOf(
+			Condition{Column: "table1.field3", Operator: ">", Values: []interface{}{3}},
+			Condition{Column: "table1.field4", Operator: "like", Values: []interface{}{"a%"}},
+		)
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("DELETE FROM table1 WHERE table1.field1=$1 AND table1.field2 IN ($2,$3) AND (table1.field3>$4 OR table1.field4 LIKE $5)", qry)
+	assert.Equal([]interface{}{"value1", 1, 2, 3, "a%"}, args)
+
+	qb = NewDelete("table1").WhereAnyOf(Condition{Column: "table1.field1", Operator: "BETWEEN", Values: []interface{}{1}})
+	assert.ErrorIs(qb.Err(), ErrBadBetweenValues)
+}
+
+func TestItCreatesAnSQLStatementWithoutPrefixingTheColumns(t *testing.T) {
+	var d struct {
+		id     int
+		field1 string
+	}
+	qry, err := NewSelect("table1").
+		DisableAutoPrefix().
+		Select("id", "t2.field1").
+		Into(&d.id, &d.field1).
+		OrderBy("id").
+		GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT id,t2.field1 FROM table1 ORDER BY id ASC", qry)
+
+	qry, err = NewUpdate("table1").
+		ForPostgres().
+		DisableAutoPrefix().
+		Set("field1").
+		To("value1").
+		Returning("id").
+		Into(&d.id).
+		GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("UPDATE table1 SET field1=$1 RETURNING id", qry)
+}
+
+func TestItCreatesAnSQLStatementFromADerivedTable(t *testing.T) {
+	var d struct {
+		userID int
+		name   string
+	}
+	qb := NewSelect("").
+		ForPostgres().
+		FromSubquery(NewSelect("orders").
+			Select("user_id").
+			Where("orders.total", ">", 100), "big").
+		Select("user_id", "users.name").
+		Into(&d.userID, &d.name).
+		JoinCond("INNER", "users", "users.id=big.user_id AND users.active=?", true).
+		Where("users.country", "=", "GR")
+	qry, args, err := qb.Build()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("SELECT big.user_id,users.name FROM (SELECT orders.user_id FROM orders WHERE orders.total>$1) big"+
+		" INNER JOIN users ON users.id=big.user_id AND users.active=$2 WHERE users.country=$3", qry)
+	assert.Equal([]interface{}{100, true, "GR"}, args)
+
+	qb.Reset()
+	qry, err = qb.Select("user_id").Into(&d.userID).GenerateQuery()
+	assert.Nil(err)
+	assert.Equal("SELECT big.user_id FROM (SELECT orders.user_id FROM orders WHERE orders.total>$1) big", qry)
+}
+
+func TestItChecksTheReturningValuesOnlyWhenIntoIsUsed(t *testing.T) {
+	qb := NewInsert("table1").
+		ForPostgres().
+		Set("field1").
+		To("value1").
+		Returning("id", "field1")
+	qry, err := qb.GenerateQuery()
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("INSERT INTO table1 (field1) VALUES ($1) RETURNING table1.id,table1.field1", qry)
+
+	var id int
+	_, err = qb.Into(&id).GenerateQuery()
+	assert.Equal(NewBadColumnsValuesComboError(2, 1), err)
+}
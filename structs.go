@@ -0,0 +1,78 @@
+package sqlquerybob
+
+import (
+	"reflect"
+	"strings"
+)
+
+// The struct tag that holds the column name of a struct field
+const columnTag = "db"
+
+// Define the table columns to be selected and the values in which the results will be
+// stored from the db tags of a struct. dest must be a pointer to a struct. Each field with
+// a db tag is added as a column, prefixed like in Select, and its address is added as a
+// value like in Into. Fields without a db tag, or tagged with db:"-", are skipped. For example
+//   - var u struct { ID int `db:"id"`; Name string `db:"name"` }
+//     NewSelect("users").SelectStruct(&u) is equivalent to
+//     NewSelect("users").Select("id", "name").Into(&u.ID, &u.Name)
+func (qb *Builder) SelectStruct(dest interface{}) (*Builder, error) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return qb, ErrNotAStructPointer
+	}
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		column, _, ok := structFieldColumn(v.Type().Field(i))
+		if !ok {
+			continue
+		}
+		qb.Select(column).Into(v.Field(i).Addr().Interface())
+	}
+	return qb, nil
+}
+
+// Define the columns and values of an insert from the db tags of a struct. src must be a
+// struct or a pointer to a struct. Each field with a db tag is added as a column and its
+// value is added as a value like in Set and To. Fields without a db tag, or tagged with
+// db:"-", are skipped, and fields tagged with the omitempty option, like db:"name,omitempty",
+// are skipped when they hold their zero value.
+func (qb *Builder) InsertStruct(src interface{}) (*Builder, error) {
+	v := reflect.ValueOf(src)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return qb, ErrNotAStruct
+	}
+	for i := 0; i < v.NumField(); i++ {
+		column, omitEmpty, ok := structFieldColumn(v.Type().Field(i))
+		if !ok || (omitEmpty && v.Field(i).IsZero()) {
+			continue
+		}
+		qb.Set(column).To(v.Field(i).Interface())
+	}
+	return qb, nil
+}
+
+// Returns the column name of a struct field from its db tag and whether the omitempty
+// option is set. ok is false if the field should not be mapped to a column.
+func structFieldColumn(field reflect.StructField) (column string, omitEmpty bool, ok bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+	tag, found := field.Tag.Lookup(columnTag)
+	if !found {
+		return "", false, false
+	}
+	parts := strings.Split(tag, ",")
+	column = parts[0]
+	if column == "" || column == "-" {
+		return "", false, false
+	}
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return column, omitEmpty, true
+}
@@ -0,0 +1,72 @@
+package sqlquerybob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItCreatesASelectStatementFromAStruct(t *testing.T) {
+	var d struct {
+		ID       int    `db:"id"`
+		Field1   string `db:"field1"`
+		Field2   int    `db:"table2.field2"`
+		Skipped  string `db:"-"`
+		Untagged string
+		field3   string `db:"field3"`
+	}
+	qb, err := NewSelect("table1").
+		Join("LEFT", "table2", "table2.table1_id", "table1.id").
+		SelectStruct(&d)
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	qry, err := qb.Where("table1.id", "=", 1).GenerateQuery()
+	expected := "SELECT table1.id,table1.field1,table2.field2" +
+		" FROM table1 LEFT JOIN table2 ON table2.table1_id=table1.id" +
+		" WHERE table1.id=?"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{&d.ID, &d.Field1, &d.Field2}, qb.Values())
+}
+
+func TestItReturnsAnErrorIfSelectStructIsNotAPointerToAStruct(t *testing.T) {
+	var d struct {
+		ID int `db:"id"`
+	}
+	var i int
+
+	assert := assert.New(t)
+	_, err := NewSelect("table1").SelectStruct(d)
+	assert.ErrorIs(err, ErrNotAStructPointer)
+	_, err = NewSelect("table1").SelectStruct(&i)
+	assert.ErrorIs(err, ErrNotAStructPointer)
+}
+
+func TestItCreatesAnInsertStatementFromAStruct(t *testing.T) {
+	type data struct {
+		ID      int    `db:"id,omitempty"`
+		Field1  string `db:"field1"`
+		Field2  int    `db:"field2"`
+		Field3  string `db:"field3,omitempty"`
+		Skipped string `db:"-"`
+	}
+	qb, err := NewInsert("table1").
+		ForDatabase(POSTGRES).
+		InsertStruct(data{Field1: "value1", Field3: "value3", Skipped: "skipped"})
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	qry, err := qb.GenerateQuery()
+	expected := "INSERT INTO table1 (field1,field2,field3) VALUES ($1,$2,$3)"
+	assert.Nil(err)
+	assert.Equal(expected, qry)
+	assert.Equal([]any{"value1", 0, "value3"}, qb.Values())
+}
+
+func TestItReturnsAnErrorIfInsertStructIsNotAStruct(t *testing.T) {
+	_, err := NewInsert("table1").InsertStruct([]string{"value1"})
+
+	assert := assert.New(t)
+	assert.ErrorIs(err, ErrNotAStruct)
+}